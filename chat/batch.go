@@ -0,0 +1,139 @@
+package chat
+
+import (
+	"context"
+	"sync"
+)
+
+// ToolCallBatch resolves the tool calls of a single completion together,
+// e.g. for UIs that review the whole plan of the model at once
+type ToolCallBatch struct {
+	calls []EventToolCall
+
+	once sync.Once
+	done chan struct{}
+}
+
+// NewToolCallBatch groups the given tool calls into a batch
+func NewToolCallBatch(calls []EventToolCall) *ToolCallBatch {
+	return &ToolCallBatch{calls: calls}
+}
+
+// Batch returns the tool calls of the completion as a batch
+func (e EventCompletionEnded) Batch() *ToolCallBatch {
+	return NewToolCallBatch(e.ToolCalls)
+}
+
+// Calls returns all tool calls of the batch in call order
+func (b *ToolCallBatch) Calls() []EventToolCall {
+	return b.calls
+}
+
+// Pending returns the tool calls which are not resolved yet, in call order
+func (b *ToolCallBatch) Pending() []EventToolCall {
+	var pending []EventToolCall
+	for _, call := range b.calls {
+		if !call.Resolved() {
+			pending = append(pending, call)
+		}
+	}
+	return pending
+}
+
+// ApproveAll approves every pending call and returns the amount of calls it resolved
+func (b *ToolCallBatch) ApproveAll() int {
+	return b.resolveIf(nil, Verdict{Accepted: true})
+}
+
+// DenyAll declines every pending call with the given reason (see EventToolCall.Decline)
+// and returns the amount of calls it resolved
+func (b *ToolCallBatch) DenyAll(reason string) int {
+	return b.resolveIf(nil, Verdict{Accepted: false, Reason: reason})
+}
+
+// ApproveIf approves the pending calls matching the predicate and returns the amount of calls it resolved.
+// The rest stays pending
+func (b *ToolCallBatch) ApproveIf(predicate func(call EventToolCall) bool) int {
+	return b.resolveIf(predicate, Verdict{Accepted: true})
+}
+
+// DenyIf declines the pending calls matching the predicate and returns the amount of calls it resolved.
+// The rest stays pending
+func (b *ToolCallBatch) DenyIf(predicate func(call EventToolCall) bool, reason string) int {
+	return b.resolveIf(predicate, Verdict{Accepted: false, Reason: reason})
+}
+
+func (b *ToolCallBatch) resolveIf(predicate func(call EventToolCall) bool, verdict Verdict) int {
+	resolved := 0
+	for _, call := range b.calls {
+		if call.Resolved() || (predicate != nil && !predicate(call)) {
+			continue
+		}
+		// calls resolved concurrently by someone else are skipped
+		if call.resolve(verdict) == nil {
+			resolved++
+		}
+	}
+	return resolved
+}
+
+// Done returns a channel that is closed once every call of the batch is resolved.
+// The channel is never closed if the session ends while some calls are still pending
+func (b *ToolCallBatch) Done() <-chan struct{} {
+	b.once.Do(func() {
+		b.done = make(chan struct{})
+		go b.watch()
+	})
+	return b.done
+}
+
+// watch closes the done channel when all calls are resolved,
+// it gives up when the session of a pending call ends
+func (b *ToolCallBatch) watch() {
+	for _, call := range b.calls {
+		if call.resolution == nil {
+			continue
+		}
+
+		var sessionDone <-chan struct{}
+		if call.approval != nil {
+			sessionDone = call.approval.ctx.Done()
+		}
+
+		select {
+		case <-call.resolution.done:
+		case <-sessionDone:
+			if !call.Resolved() {
+				return
+			}
+		}
+	}
+	close(b.done)
+}
+
+// Wait blocks until every call of the batch is resolved.
+// It returns ErrSessionEnded if the session ends while some calls are still pending
+func (b *ToolCallBatch) Wait(ctx context.Context) error {
+	select {
+	case <-b.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.sessionDone():
+		// the session may end right after the last call was resolved
+		if len(b.Pending()) == 0 {
+			return nil
+		}
+		return ErrSessionEnded
+	}
+}
+
+// sessionDone returns the done channel of the session owning the calls, nil if there is none
+func (b *ToolCallBatch) sessionDone() <-chan struct{} {
+	for _, call := range b.calls {
+		if call.approval != nil {
+			return call.approval.ctx.Done()
+		}
+	}
+	return nil
+}
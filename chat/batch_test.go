@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func TestToolCallBatch_Resolve(t *testing.T) {
+	batch := NewToolCallBatch([]EventToolCall{
+		NewEventToolCall("call-1", "read", `{}`),
+		NewEventToolCall("call-2", "write", `{}`),
+		NewEventToolCall("call-3", "read", `{}`),
+	})
+
+	require.Len(t, batch.Pending(), 3)
+
+	approved := batch.ApproveIf(func(call EventToolCall) bool { return call.Name == "read" })
+	assert.Equal(t, 2, approved)
+
+	pending := batch.Pending()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "call-2", pending[0].CallID)
+
+	select {
+	case <-batch.Done():
+		t.Fatal("batch must not be done while calls are pending")
+	default:
+	}
+
+	assert.Equal(t, 1, batch.DenyAll("no"))
+	assert.Equal(t, 0, batch.ApproveAll(), "resolved calls are skipped")
+	assert.Empty(t, batch.Pending())
+
+	select {
+	case <-batch.Done():
+	case <-time.After(time.Second):
+		t.Fatal("batch must be done once every call is resolved")
+	}
+	assert.NoError(t, batch.Wait(context.Background()))
+}
+
+func TestToolCallBatch_SharesStateWithCalls(t *testing.T) {
+	call := NewEventToolCall("call-1", "read", `{}`)
+	batch := NewToolCallBatch([]EventToolCall{call})
+
+	require.NoError(t, call.Resolve(true))
+	assert.Empty(t, batch.Pending())
+	assert.Equal(t, 0, batch.ApproveAll())
+}
+
+func TestSession_BatchApproval(t *testing.T) {
+	c := &Chat{
+		Messages: NewMessages(),
+		Tools:    tools.NewTools(),
+	}
+	for _, name := range []string{"read", "write"} {
+		tool, err := tools.NewTool(name, "", func(input map[string]string) (string, error) {
+			return "done", nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, c.Tools.Add(tool))
+	}
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "read", `{}`),
+			NewEventToolCall("call-2", "write", `{}`),
+			NewEventToolCall("call-3", "read", `{}`),
+		},
+		{},
+	})
+
+	waited := make(chan error, 1)
+	for event := range c.Session(context.Background(), mockClient) {
+		ended, ok := event.(EventCompletionEnded)
+		if !ok || len(ended.ToolCalls) == 0 {
+			continue
+		}
+
+		batch := ended.Batch()
+		go func() { waited <- batch.Wait(context.Background()) }()
+
+		assert.Equal(t, 2, batch.ApproveIf(func(call EventToolCall) bool { return call.Name == "read" }))
+		assert.Equal(t, 1, batch.DenyAll("writes are disabled"))
+	}
+
+	assert.NoError(t, <-waited)
+
+	messages := withoutMetadata(c.Messages.Snapshot())
+	assert.Contains(t, messages, NewEventToolMessage("call-1", "done", true))
+	assert.Contains(t, messages, NewEventToolMessage("call-2", "writes are disabled", false))
+	assert.Contains(t, messages, NewEventToolMessage("call-3", "done", true))
+}
+
+func TestToolCallBatch_WaitSessionEnded(t *testing.T) {
+	c := &Chat{
+		Messages: NewMessages(),
+		Tools:    tools.NewTools(),
+	}
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "read", `{}`)},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var batch *ToolCallBatch
+	for event := range c.Session(ctx, mockClient) {
+		if ended, ok := event.(EventCompletionEnded); ok {
+			batch = ended.Batch()
+			cancel()
+		}
+	}
+
+	require.NotNil(t, batch)
+	assert.ErrorIs(t, batch.Wait(context.Background()), ErrSessionEnded)
+	assert.Len(t, batch.Pending(), 1)
+}
@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"slices"
+	"sync"
+)
+
+const DefaultBranch = "main"
+
+// Fork returns new messages holding the first n events, e.g. to explore an alternative continuation.
+// A negative n or n beyond the length forks all the events
+func (m *Messages) Fork(n int) *Messages {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n < 0 || n > len(m.Events) {
+		n = len(m.Events)
+	}
+	return &Messages{Events: slices.Clone(m.Events[:n])}
+}
+
+// Fork returns a copy of the chat whose history is a fork of its first n messages (see Messages.Fork).
+// The copy shares the tools and the configuration of the chat
+func (c *Chat) Fork(n int) *Chat {
+	fork := *c
+	fork.Messages = c.Messages.Fork(n)
+	fork.calls = nil
+	return &fork
+}
+
+// Branch describes a branch of a Tree
+type Branch struct {
+	Name string
+	// Parent is the branch the branch was forked from (empty for DefaultBranch)
+	Parent string
+	// At is the amount of messages shared with the parent
+	At int
+	// Length is the amount of messages on the branch, including the shared ones
+	Length int
+}
+
+// Tree holds alternative continuations of a chat as named branches.
+// The active branch is the Messages of the chat, so sessions and SyncInput operate on the active path.
+// Branches must not be switched while a session of the chat is running
+type Tree struct {
+	chat *Chat
+
+	mu       sync.Mutex
+	branches map[string]*branchNode
+	order    []string
+	active   string
+}
+
+type branchNode struct {
+	parent   string
+	at       int
+	messages *Messages
+}
+
+// NewTree starts a tree with the current messages of the chat as DefaultBranch
+func NewTree(c *Chat) *Tree {
+	if c.Messages == nil {
+		c.Messages = NewMessages()
+	}
+	return &Tree{
+		chat:     c,
+		branches: map[string]*branchNode{DefaultBranch: {messages: c.Messages}},
+		order:    []string{DefaultBranch},
+		active:   DefaultBranch,
+	}
+}
+
+// Fork creates a branch sharing the first n messages of the active branch and makes it active
+// (see Messages.Fork for the range of n). It returns ErrBranchExists if the name is taken
+func (t *Tree) Fork(name string, n int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.branches[name]; ok {
+		return ErrBranchExists
+	}
+
+	messages := t.branches[t.active].messages.Fork(n)
+	t.branches[name] = &branchNode{parent: t.active, at: len(messages.Events), messages: messages}
+	t.order = append(t.order, name)
+	t.checkout(name)
+	return nil
+}
+
+// Checkout makes the branch active. It returns ErrBranchNotFound if there's no such branch
+func (t *Tree) Checkout(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.branches[name]; !ok {
+		return ErrBranchNotFound
+	}
+	t.checkout(name)
+	return nil
+}
+
+func (t *Tree) checkout(name string) {
+	t.active = name
+	t.chat.Messages = t.branches[name].messages
+}
+
+// Delete removes the branch, the branches forked from it keep their messages.
+// The active branch can't be deleted (ErrActiveBranch)
+func (t *Tree) Delete(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.branches[name]; !ok {
+		return ErrBranchNotFound
+	}
+	if name == t.active {
+		return ErrActiveBranch
+	}
+
+	delete(t.branches, name)
+	t.order = slices.DeleteFunc(t.order, func(n string) bool { return n == name })
+	return nil
+}
+
+// Active returns the name of the active branch
+func (t *Tree) Active() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active
+}
+
+// Messages returns the messages of the branch
+func (t *Tree) Messages(name string) (*Messages, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	node, ok := t.branches[name]
+	if !ok {
+		return nil, false
+	}
+	return node.messages, true
+}
+
+// Branches describes the branches in the order they were created
+func (t *Tree) Branches() []Branch {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]Branch, 0, len(t.order))
+	for _, name := range t.order {
+		node := t.branches[name]
+		result = append(result, Branch{
+			Name:   name,
+			Parent: node.parent,
+			At:     node.at,
+			Length: len(node.messages.Snapshot()),
+		})
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func TestMessages_Fork(t *testing.T) {
+	m := NewMessages()
+	m.AddEvent(NewEventUserMessage("a"))
+	m.AddEvent(NewEventAssistantMessage("b"))
+
+	fork := m.Fork(1)
+	fork.AddEvent(NewEventUserMessage("c"))
+
+	assert.Equal(t, []StreamEvent{NewEventUserMessage("a"), NewEventUserMessage("c")}, fork.Snapshot())
+	assert.Equal(t, []StreamEvent{NewEventUserMessage("a"), NewEventAssistantMessage("b")}, m.Snapshot())
+	assert.Len(t, m.Fork(-1).Snapshot(), 2)
+	assert.Len(t, m.Fork(10).Snapshot(), 2)
+}
+
+func TestChat_Fork(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools(), MaxRounds: 3}
+	c.AddMessage(SenderUser{}, "hi")
+
+	fork := c.Fork(0)
+	assert.Empty(t, fork.Messages.Snapshot())
+	assert.Same(t, c.Tools, fork.Tools)
+	assert.Equal(t, 3, fork.MaxRounds)
+	assert.Len(t, c.Messages.Snapshot(), 1)
+}
+
+func TestTree(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	c.AddMessage(SenderSystem{}, "be nice")
+	c.AddMessage(SenderUser{}, "tell me a joke")
+	tree := NewTree(c)
+
+	require.NoError(t, tree.Fork("alt", 1))
+	assert.Equal(t, "alt", tree.Active())
+	c.AddMessage(SenderUser{}, "tell me a poem")
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{NewEventToken("roses are red")}})
+	for range c.Session(context.Background(), mockClient) {
+	}
+
+	// the session operates on the active path
+	assert.Equal(t, []StreamEvent{
+		NewEventSystemMessage("be nice"),
+		NewEventUserMessage("tell me a poem"),
+	}, withoutMetadata(mockClient.SyncedChat.Messages.Snapshot())[:2])
+	assert.Equal(t, NewEventAssistantMessage("roses are red"), withoutMetadata(c.Messages.Snapshot())[2])
+
+	main, ok := tree.Messages(DefaultBranch)
+	require.True(t, ok)
+	assert.Len(t, main.Snapshot(), 2, "other branches are not changed")
+
+	require.NoError(t, tree.Checkout(DefaultBranch))
+	assert.Same(t, main, c.Messages)
+
+	assert.Equal(t, []Branch{
+		{Name: DefaultBranch, Length: 2},
+		{Name: "alt", Parent: DefaultBranch, At: 1, Length: 3},
+	}, tree.Branches())
+}
+
+func TestTree_Errors(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	tree := NewTree(c)
+
+	assert.ErrorIs(t, tree.Fork(DefaultBranch, 0), ErrBranchExists)
+	assert.ErrorIs(t, tree.Checkout("missing"), ErrBranchNotFound)
+	assert.ErrorIs(t, tree.Delete(DefaultBranch), ErrActiveBranch)
+	assert.ErrorIs(t, tree.Delete("missing"), ErrBranchNotFound)
+
+	require.NoError(t, tree.Fork("alt", -1))
+	require.NoError(t, tree.Delete(DefaultBranch))
+	assert.Equal(t, []Branch{{Name: "alt", Parent: DefaultBranch}}, tree.Branches())
+}
@@ -1,14 +1,22 @@
 package chat
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/x2d7/interlude/chat/tools"
 )
 
 const DefaultDeclinedToolMessage = "Tool call declined"
 
+const DefaultFinalAnswerPrompt = "Tool call limit reached. Do not call any more tools — " +
+	"answer the user with the information you already have."
+
 func (c *Chat) Complete(ctx context.Context, client Client) <-chan StreamEvent {
 	result := make(chan StreamEvent, 16)
 
@@ -42,28 +50,64 @@ func (c *Chat) Complete(ctx context.Context, client Client) <-chan StreamEvent {
 	return result
 }
 
+// sessionOptions alter the behaviour of a single session
+type sessionOptions struct {
+	// resume re-surfaces dangling tool calls before the first completion (see Chat.Resume)
+	resume bool
+	// interrupt stops the session once it's closed, keeping the partial output (see SessionHandle.Interrupt)
+	interrupt <-chan struct{}
+	// steering holds the user messages enqueued by the consumer (see SessionHandle.Steer)
+	steering *steeringQueue
+	// policy replaces Chat.ApprovalPolicy for the session
+	policy ApprovalPolicy
+}
+
 type sessionState struct {
 	// session context
 
+	chat   *Chat
 	client Client
 	events <-chan StreamEvent
 	send   func(StreamEvent) bool
 	ctx    context.Context
 
+	interrupt <-chan struct{}
+	steering  *steeringQueue
+	policy    ApprovalPolicy
+
 	// session state variables
 
 	builder         strings.Builder
 	thinkingBuilder strings.Builder
 	toolCalls       []EventToolCall
 	lastToolCall    *EventToolCall
+	// droppedToolCall is set when the hooks dropped the last tool call of the stream (see Hooks.OnEvent)
+	droppedToolCall bool
 	approval        *ApproveWaiter
+	timers          []*time.Timer
+	failed          bool
+	// details of the current completion, added to the metadata of its messages
+	generation Metadata
+	started    time.Time
+
+	// session-wide counters (not affected by reset)
+
+	rounds        int
+	lastCallKey   string
+	repeatedCalls int
+	final         bool
 }
 
 func (s *sessionState) reset() {
+	s.stopTimers()
+	s.chat.calls.remove(s.toolCalls)
 	s.builder.Reset()
 	s.thinkingBuilder.Reset()
 	s.toolCalls = s.toolCalls[:0]
 	s.lastToolCall = nil
+	s.droppedToolCall = false
+	s.failed = false
+	s.generation = Metadata{}
 	s.approval = NewApproveWaiter(s.ctx)
 }
 
@@ -71,9 +115,89 @@ func (s *sessionState) flushLastToolCall() bool {
 	if s.lastToolCall == nil {
 		return true
 	}
-	ok := s.send(*s.lastToolCall)
+	s.chat.describeToolCall(s.lastToolCall)
+	call := *s.lastToolCall
 	s.lastToolCall = nil
-	return ok
+
+	// arguments are complete now, so the approval policy can decide on the call.
+	// The call is claimed before it's sent, so the consumer can't resolve it first
+	verdict, decided := s.chat.decideApprovalWith(s.policy, call)
+	if incompleteArguments(call) {
+		verdict, decided = Verdict{Accepted: false, Reason: DefaultIncompleteToolMessage}, true
+	}
+	decided = decided && call.claim()
+	s.chat.calls.add(call)
+
+	if !s.send(call) {
+		return false
+	}
+
+	if decided {
+		call.deliver(verdict)
+	} else {
+		s.watchApproval(call)
+	}
+	return s.ctx.Err() == nil
+}
+
+// completion returns the details of the current completion
+func (s *sessionState) completion() Metadata {
+	generation := s.generation
+	generation.Latency = time.Since(s.started)
+	return generation
+}
+
+// trackToolCalls updates the counter of identical tool calls issued in a row
+func (s *sessionState) trackToolCalls() {
+	for _, call := range s.toolCalls {
+		key := toolCallKey(call)
+		if key == s.lastCallKey {
+			s.repeatedCalls++
+			continue
+		}
+		s.lastCallKey = key
+		s.repeatedCalls = 1
+	}
+}
+
+// toolCallKey identifies a tool call by its name and arguments, ignoring insignificant whitespace
+func toolCallKey(call EventToolCall) string {
+	var args bytes.Buffer
+	if err := json.Compact(&args, []byte(call.Content)); err != nil {
+		return call.Name + "\x00" + call.Content
+	}
+	return call.Name + "\x00" + args.String()
+}
+
+// checkLimits returns a limit event if the session must not start another regular round
+func (c *Chat) checkLimits(state *sessionState) *EventLimitReached {
+	var limit EventLimitReached
+	switch {
+	case c.MaxIdenticalToolCalls > 0 && state.repeatedCalls >= c.MaxIdenticalToolCalls:
+		limit = NewEventLimitReached(LimitToolCallLoop, state.rounds)
+	case c.MaxRounds > 0 && state.rounds >= c.MaxRounds:
+		limit = NewEventLimitReached(LimitMaxRounds, state.rounds)
+	default:
+		return nil
+	}
+	return &limit
+}
+
+// finalAnswerChat returns a copy of the chat without tools and with the final answer prompt appended.
+// Used for the last completion after a limit was reached, so the history of the chat stays untouched
+func (c *Chat) finalAnswerChat() *Chat {
+	prompt := c.FinalAnswerPrompt
+	if prompt == "" {
+		prompt = DefaultFinalAnswerPrompt
+	}
+
+	messages := NewMessages()
+	messages.Events = append(c.Messages.Snapshot(), NewEventSystemMessage(prompt))
+
+	return &Chat{
+		Messages: messages,
+		Tools:    tools.NewTools(),
+	}
 }
 
 func (c *Chat) ensureDefaults() {
@@ -84,15 +208,34 @@ func (c *Chat) ensureDefaults() {
 		t := tools.NewTools()
 		c.Tools = t
 	}
+	if c.calls == nil {
+		c.calls = newCallRegistry()
+	}
 }
 
 func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
+	return c.session(ctx, client, sessionOptions{})
+}
+
+func (c *Chat) session(ctx context.Context, client Client, opts sessionOptions) <-chan StreamEvent {
 	// ensuring default values
 	c.ensureDefaults()
 
+	// the output is coalesced until the caller's context is done, not the session's one
+	parent := ctx
+
+	// the session context is cancelled when the session ends,
+	// releasing everything that still waits for it (approvals, timers, tools)
+	ctx, cancel := context.WithCancel(ctx)
+
 	// creating the channels
 	result := make(chan StreamEvent, 16)
 
+	// guards the result channel from events sent after the session ended
+	// (e.g. by the consumer resolving a call that is no longer awaited)
+	var closeMu sync.RWMutex
+	closed := false
+
 	// delivers a StreamEvent to the result channel
 	// skips nil events
 	send := func(event StreamEvent) bool {
@@ -102,6 +245,13 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 			}
 			return true
 		}
+
+		closeMu.RLock()
+		defer closeMu.RUnlock()
+		if closed {
+			return false
+		}
+
 		select {
 		case result <- event:
 			return true
@@ -112,12 +262,36 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 
 	// event handling
 	go func() {
-		defer close(result)
+		defer func() {
+			closeMu.Lock()
+			closed = true
+			close(result)
+			closeMu.Unlock()
+		}()
+		defer cancel()
 
 		// session state
 		state := &sessionState{
-			send: send,
-			ctx:  ctx,
+			chat:      c,
+			send:      send,
+			ctx:       ctx,
+			interrupt: opts.interrupt,
+			steering:  opts.steering,
+			policy:    c.ApprovalPolicy,
+		}
+		if opts.policy != nil {
+			state.policy = opts.policy
+		}
+		defer func() {
+			state.stopTimers()
+			state.steering.close()
+			c.commitSteering(state)
+			c.calls.remove(state.toolCalls)
+		}()
+
+		// answering the tool calls left in the history by a previous session
+		if opts.resume && !c.resumeToolCalls(ctx, state) {
+			return
 		}
 
 		// flag to start completion this iteration
@@ -125,6 +299,26 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 
 		for {
 			if restart {
+				if state.interrupted() {
+					send(NewEventInterrupted())
+					return
+				}
+
+				// user messages enqueued during the previous round
+				if !c.applySteering(state) {
+					return
+				}
+
+				// check round limits before starting a new completion
+				if !state.final {
+					if limit := c.checkLimits(state); limit != nil {
+						if !send(*limit) || !c.FinalAnswerOnLimit {
+							return
+						}
+						state.final = true
+					}
+				}
+
 				// send completion start event
 				if !send(NewEventCompletionStart()) {
 					return
@@ -132,12 +326,23 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 
 				// reset state
 				state.reset()
+				state.rounds++
 
 				// insert chat context into client input configuration
-				client := client.SyncInput(c)
+				syncChat := c
+				if state.final {
+					syncChat = c.finalAnswerChat()
+				}
+				client, err := c.syncInput(ctx, client, syncChat, state.rounds)
+				if err != nil {
+					c.onError(ctx, err)
+					send(NewEventError(err))
+					return
+				}
 				state.client = client
 
 				// start completion
+				state.started = time.Now()
 				state.events = c.Complete(ctx, client)
 				restart = false
 			}
@@ -145,6 +350,9 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 			select {
 			case <-ctx.Done():
 				return
+			case <-state.interrupt:
+				c.interruptCompletion(state)
+				return
 			case ev, ok := <-state.events:
 				if !ok {
 					if !c.handleCompletionEnd(ctx, state) {
@@ -154,8 +362,30 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 					continue
 				}
 
-				// flush last tool call if event type switched away from tool call stream
-				if _, isToolCall := ev.(EventToolCall); !isToolCall {
+				// the argument fragments of a tool call dropped by the hooks are dropped with it
+				if call, ok := ev.(EventToolCall); ok && call.CallID == "" && state.droppedToolCall {
+					continue
+				}
+
+				// hooks may rewrite or drop the event
+				filtered := c.onEvent(ctx, ev)
+				if call, ok := ev.(EventToolCall); ok && call.CallID != "" {
+					state.droppedToolCall = filtered == nil
+				}
+				if ev = filtered; ev == nil {
+					continue
+				}
+
+				// the final round offers no tools, calls the model makes anyway are neither decided nor surfaced
+				if _, ok := ev.(EventToolCall); ok && state.final {
+					continue
+				}
+
+				// flush last tool call if event type switched away from tool call stream.
+				// A tool call interrupted by an error is incomplete, it's never flushed
+				switch ev.(type) {
+				case EventToolCall, EventError:
+				default:
 					if !state.flushLastToolCall() {
 						return
 					}
@@ -177,23 +407,18 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 							return
 						}
 
-						// inject callback
-						event.onResolved = func(callID string, accepted bool) {
-							send(EventToolCallResolved{
-								CallID:   callID,
-								Accepted: accepted,
-							})
-						}
-
-						state.approval.Attach(&event)
-						state.toolCalls = append(state.toolCalls, event)
-						state.lastToolCall = &state.toolCalls[len(state.toolCalls)-1]
+						state.addToolCall(event)
 
 						// send tool call token
 						if !state.send(NewEventToolCallToken(event.CallID, event.Name, event.Content)) {
 							return
 						}
 					} else {
+						// a fragment without a call to continue can't be used
+						if state.lastToolCall == nil {
+							continue
+						}
+
 						// add token to the last tool call
 						state.lastToolCall.Content += event.Content
 
@@ -210,6 +435,14 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 					c.AppendEvent(event)
 				case EventThinking:
 					state.thinkingBuilder.WriteString(event.Content)
+				case EventUsage:
+					state.generation.Usage = state.generation.Usage.Add(event.Usage)
+				case EventGeneration:
+					state.generation.Model = event.Model
+					state.generation.GenerationID = event.GenerationID
+				case EventError:
+					c.onError(ctx, event.Error)
+					state.failed = true
 				}
 
 				// skipping event
@@ -225,65 +458,73 @@ func (c *Chat) Session(ctx context.Context, client Client) <-chan StreamEvent {
 		}
 	}()
 
-	return result
+	// merging token events for the consumer
+	return Coalesce(parent, result, c.TokenCoalescing)
+}
+
+// addToolCall wires the call to the session and makes it the last tool call of the completion
+func (s *sessionState) addToolCall(event EventToolCall) {
+	// stamped before it's sent, so the consumer sees the stored call
+	event = stamp(event)
+
+	event.tools = s.chat.Tools
+
+	s.approval.Attach(&event)
+	s.toolCalls = append(s.toolCalls, event)
+	s.lastToolCall = &s.toolCalls[len(s.toolCalls)-1]
 }
 
 func (c *Chat) handleCompletionEnd(ctx context.Context, state *sessionState) (proceed bool) {
 	proceed = false
+
+	if state.failed {
+		return c.handleFailedCompletion(state)
+	}
+
 	// adding collected events to the chat (reasoning, assistant's tokens and tool calls)
+	generation := state.completion()
 	if state.thinkingBuilder.Len() != 0 {
-		c.AppendEvent(NewEventReasoningMessage(state.thinkingBuilder.String()))
+		c.AppendEvent(withGeneration(NewEventReasoningMessage(state.thinkingBuilder.String()), generation))
 	}
 	if state.builder.Len() != 0 {
-		c.AppendEvent(NewEventAssistantMessage(state.builder.String()))
-	}
-	for _, call := range state.toolCalls {
-		c.AppendEvent(call)
+		c.AppendEvent(withGeneration(NewEventAssistantMessage(state.builder.String()), generation))
 	}
 
-	callAmount := len(state.toolCalls)
-
 	// send last tool call if it wasn't sent yet
 	if !state.flushLastToolCall() {
 		return
 	}
 
-	// ending current completion
-	if !state.send(NewEventCompletionEnded(state.toolCalls)) {
-		return
+	for i, call := range state.toolCalls {
+		state.toolCalls[i] = withGeneration(call, generation)
+		c.AppendEvent(state.toolCalls[i])
+	}
+
+	state.trackToolCalls()
+
+	return c.awaitToolCalls(ctx, state)
+}
+
+// awaitToolCalls ends the completion, waits for the verdicts on its tool calls and executes them.
+// It returns false if the session must end
+func (c *Chat) awaitToolCalls(ctx context.Context, state *sessionState) (proceed bool) {
+	callAmount := len(state.toolCalls)
+
+	// ending current completion, the calls are copied since the state is reused by the next round
+	ended := NewEventCompletionEnded(slices.Clone(state.toolCalls))
+	c.onCompletionEnd(ctx, ended)
+	if !state.send(ended) {
+		return false
 	}
 
+	// the session ends here, unless the user enqueued messages in the meantime
 	if callAmount == 0 {
-		return
+		return !state.final && state.steering.pending()
 	}
 
 	// initializing approval waiter
 	verdicts := state.approval.Wait(ctx, callAmount)
 
 	// processing user verdicts
-	for verdict := range verdicts {
-		call := verdict.call
-
-		var toolMessage EventToolMessage
-
-		if verdict.Accepted {
-			callResult, success := c.Tools.Execute(call.Name, call.Content)
-			toolMessage = NewEventToolMessage(call.CallID, callResult, success)
-		} else {
-			msg := c.DeclinedToolMessage
-			if msg == "" {
-				msg = DefaultDeclinedToolMessage
-			}
-			toolMessage = NewEventToolMessage(call.CallID, msg, false)
-		}
-		// adding tool message to the chat
-		c.AppendEvent(toolMessage)
-
-		// sending tool message
-		if !state.send(toolMessage) {
-			return
-		}
-	}
-
-	return true
+	return c.executeTools(ctx, state, verdicts)
 }
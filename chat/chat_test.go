@@ -62,6 +62,49 @@ func (s *MockStream) Close() error {
 	return nil
 }
 
+// withoutMetadata drops the metadata of the message events, so they can be compared with constructed events
+func withoutMetadata[T StreamEvent](events []T) []T {
+	if events == nil {
+		return nil
+	}
+	result := make([]T, len(events))
+	for i, event := range events {
+		result[i] = stripMetadata(event)
+	}
+	return result
+}
+
+func stripMetadata[T StreamEvent](event T) T {
+	if message, ok := any(event).(MessageEvent); ok {
+		return message.withMetadata(Metadata{}).(T)
+	}
+	return event
+}
+
+// newToolChat returns a chat with a single tool which takes a map[string]string input and returns the result.
+// The counter reports how many times the tool was executed
+func newToolChat(t *testing.T, name, result string, opts ...tools.AddOption) (*Chat, *atomic.Int32) {
+	t.Helper()
+
+	execCount := &atomic.Int32{}
+	c := &Chat{
+		Messages: NewMessages(),
+		Tools:    tools.NewTools(),
+	}
+	tool, err := tools.NewTool(name, "Test tool", func(input map[string]string) (string, error) {
+		execCount.Add(1)
+		return result, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tool: %v", err)
+	}
+	if err := c.Tools.Add(tool, opts...); err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+
+	return c, execCount
+}
+
 // MockClient implements Client for testing
 type MockClient struct {
 	StreamingEvents []StreamEvent
@@ -2258,3 +2301,170 @@ func TestSession_ToolCall_DoubleResolveFromCopies(t *testing.T) {
 
 	assert.Equal(t, int32(1), execCount.Load(), "tool must be executed exactly once")
 }
+
+// ==================== Session Tests - Limits ====================
+
+func collectResolving(events <-chan StreamEvent) []StreamEvent {
+	var received []StreamEvent
+	for event := range events {
+		received = append(received, event)
+		if tc, ok := event.(EventToolCall); ok {
+			tc.Resolve(true)
+		}
+	}
+	return received
+}
+
+func findLimitEvent(events []StreamEvent) *EventLimitReached {
+	for _, event := range events {
+		if e, ok := event.(EventLimitReached); ok {
+			return &e
+		}
+	}
+	return nil
+}
+
+func TestSession_MaxRounds_StopsSession(t *testing.T) {
+	c, execCount := newToolChat(t, "test-tool", "result")
+	c.MaxRounds = 2
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{"key": "1"}`)},
+		{NewEventToolCall("call-2", "test-tool", `{"key": "2"}`)},
+		{NewEventToolCall("call-3", "test-tool", `{"key": "3"}`)},
+		{NewEventToolCall("call-4", "test-tool", `{"key": "4"}`)},
+	})
+
+	received := collectResolving(c.Session(context.Background(), mockClient))
+
+	limit := findLimitEvent(received)
+	if assert.NotNil(t, limit) {
+		assert.Equal(t, LimitMaxRounds, limit.Limit)
+		assert.Equal(t, 2, limit.Rounds)
+		assert.ErrorIs(t, limit.Err(), ErrMaxRoundsReached)
+	}
+	assert.Equal(t, int32(2), execCount.Load())
+	assert.IsType(t, EventLimitReached{}, received[len(received)-1])
+}
+
+func TestSession_MaxRounds_NotReached(t *testing.T) {
+	c, _ := newToolChat(t, "test-tool", "result")
+	c.MaxRounds = 5
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{"key": "1"}`)},
+		{NewEventToken("done")},
+	})
+
+	received := collectResolving(c.Session(context.Background(), mockClient))
+
+	assert.Nil(t, findLimitEvent(received))
+}
+
+func TestSession_IdenticalToolCalls_DetectsLoop(t *testing.T) {
+	c, execCount := newToolChat(t, "test-tool", "result")
+	c.MaxIdenticalToolCalls = 3
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{"key": "same"}`)},
+		{NewEventToolCall("call-2", "test-tool", `{"key":"same"}`)},
+		{NewEventToolCall("call-3", "test-tool", `{ "key" : "same" }`)},
+		{NewEventToolCall("call-4", "test-tool", `{"key": "same"}`)},
+	})
+
+	received := collectResolving(c.Session(context.Background(), mockClient))
+
+	limit := findLimitEvent(received)
+	if assert.NotNil(t, limit) {
+		assert.Equal(t, LimitToolCallLoop, limit.Limit)
+		assert.ErrorIs(t, limit.Err(), ErrToolCallLoop)
+	}
+	assert.Equal(t, int32(3), execCount.Load())
+}
+
+func TestSession_IdenticalToolCalls_DifferentArgumentsResetCounter(t *testing.T) {
+	c, execCount := newToolChat(t, "test-tool", "result")
+	c.MaxIdenticalToolCalls = 2
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{"key": "a"}`)},
+		{NewEventToolCall("call-2", "test-tool", `{"key": "b"}`)},
+		{NewEventToolCall("call-3", "test-tool", `{"key": "a"}`)},
+		{NewEventToken("done")},
+	})
+
+	received := collectResolving(c.Session(context.Background(), mockClient))
+
+	assert.Nil(t, findLimitEvent(received))
+	assert.Equal(t, int32(3), execCount.Load())
+}
+
+func TestSession_FinalAnswerOnLimit(t *testing.T) {
+	c, execCount := newToolChat(t, "test-tool", "result")
+	c.MaxRounds = 1
+	c.FinalAnswerOnLimit = true
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{"key": "1"}`)},
+		{NewEventToken("final answer"), NewEventToolCall("call-2", "test-tool", `{"key": "2"}`)},
+	})
+
+	received := collectResolving(c.Session(context.Background(), mockClient))
+
+	assert.NotNil(t, findLimitEvent(received))
+	assert.Equal(t, int32(1), execCount.Load())
+
+	// the final round is synced without tools and with the final answer prompt
+	synced := mockClient.SyncedChat
+	assert.Empty(t, synced.Tools.Snapshot())
+	last := synced.Messages.Snapshot()[len(synced.Messages.Snapshot())-1]
+	assert.Equal(t, NewEventSystemMessage(DefaultFinalAnswerPrompt), last)
+
+	// the prompt is not stored, the answer is; tool calls of the final round are dropped
+	messages := withoutMetadata(c.Messages.Snapshot())
+	assert.Equal(t, NewEventAssistantMessage("final answer"), messages[len(messages)-1])
+	for _, msg := range messages {
+		if tc, ok := msg.(EventToolCall); ok {
+			assert.NotEqual(t, "call-2", tc.CallID)
+		}
+	}
+}
+
+func TestSession_FinalAnswerIgnoresToolCalls(t *testing.T) {
+	c, _ := newToolChat(t, "test-tool", "result")
+	c.MaxRounds = 1
+	c.FinalAnswerOnLimit = true
+	c.ApprovalTimeout = 10 * time.Millisecond
+
+	var decided []string
+	c.ApprovalPolicy = ApprovalPolicyFunc(func(req ApprovalRequest) ApprovalResult {
+		decided = append(decided, req.Call.CallID)
+		return ApprovalResult{Decision: ApprovalApprove}
+	})
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{NewEventToken("final answer"), NewEventToolCall("call-2", "test-tool", `{}`)},
+	})
+
+	var received []StreamEvent
+	for event := range c.Session(context.Background(), mockClient) {
+		received = append(received, event)
+	}
+
+	// the consumer is never asked about the calls of the final round
+	assert.Equal(t, []string{"call-1"}, decided)
+	for _, event := range received {
+		switch e := event.(type) {
+		case EventToolCall:
+			assert.NotEqual(t, "call-2", e.CallID)
+		case EventToolCallToken:
+			assert.NotEqual(t, "call-2", e.CallID)
+		case EventApprovalExpired:
+			t.Errorf("unexpected approval timeout of %s", e.CallID)
+		}
+	}
+	if ended, ok := received[len(received)-1].(EventCompletionEnded); assert.True(t, ok) {
+		assert.Empty(t, ended.ToolCalls)
+	}
+}
@@ -0,0 +1,181 @@
+package chat
+
+import (
+	"context"
+	"time"
+)
+
+// CoalesceOptions configure merging of consecutive token events (EventToken, EventThinking, EventToolCallToken),
+// e.g. for renderers which can't keep up with an event per provider chunk. The zero value disables coalescing
+type CoalesceOptions struct {
+	// Window is the longest time a token waits for the next ones before it's delivered.
+	// Without a window only the tokens which are already available are merged
+	Window time.Duration
+	// MaxBytes delivers the merged tokens once their content reaches the size (0 means no limit)
+	MaxBytes int
+}
+
+func (o CoalesceOptions) enabled() bool {
+	return o.Window > 0 || o.MaxBytes > 0
+}
+
+// Coalesce merges consecutive token events of the stream according to the options.
+// Tokens are merged only with tokens of the same kind (and the same call for EventToolCallToken),
+// every other event flushes the merged tokens first, so the order of the events is preserved.
+// The returned channel is closed when the events channel is closed or ctx is done;
+// the remaining events are drained, so the producer must close the channel once ctx is done
+func Coalesce(ctx context.Context, events <-chan StreamEvent, opts CoalesceOptions) <-chan StreamEvent {
+	if !opts.enabled() {
+		return events
+	}
+
+	out := make(chan StreamEvent, 16)
+
+	go func() {
+		defer close(out)
+
+		var pending StreamEvent
+		var timer *time.Timer
+		var timeout <-chan time.Time
+
+		emit := func(event StreamEvent) bool {
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+			if pending == nil {
+				return true
+			}
+			event := pending
+			pending = nil
+			return emit(event)
+		}
+
+		// releases the producer, so it can notice the cancellation and close the channel
+		defer func() {
+			for range events {
+			}
+		}()
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			var event StreamEvent
+			var ok bool
+
+			if pending != nil && opts.Window <= 0 {
+				// without a window, tokens are merged only while they are already available
+				select {
+				case event, ok = <-events:
+				default:
+					if !flush() {
+						return
+					}
+					continue
+				}
+			} else {
+				select {
+				case event, ok = <-events:
+				case <-timeout:
+					if !flush() {
+						return
+					}
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if !ok {
+				flush()
+				return
+			}
+
+			if merged, mergeable := mergeTokens(pending, event); mergeable {
+				pending = merged
+				if opts.MaxBytes > 0 && tokenSize(pending) >= opts.MaxBytes && !flush() {
+					return
+				}
+				continue
+			}
+
+			if !flush() {
+				return
+			}
+
+			if !isToken(event) {
+				if !emit(event) {
+					return
+				}
+				continue
+			}
+
+			pending = event
+			if opts.MaxBytes > 0 && tokenSize(pending) >= opts.MaxBytes {
+				if !flush() {
+					return
+				}
+				continue
+			}
+			if opts.Window > 0 {
+				timer = time.NewTimer(opts.Window)
+				timeout = timer.C
+			}
+		}
+	}()
+
+	return out
+}
+
+func isToken(event StreamEvent) bool {
+	switch event.(type) {
+	case EventToken, EventThinking, EventToolCallToken:
+		return true
+	default:
+		return false
+	}
+}
+
+func tokenSize(event StreamEvent) int {
+	switch e := event.(type) {
+	case EventToken:
+		return len(e.Content)
+	case EventThinking:
+		return len(e.Content)
+	case EventToolCallToken:
+		return len(e.Content)
+	default:
+		return 0
+	}
+}
+
+// mergeTokens appends the token to the pending one, it returns false if they can't be merged
+func mergeTokens(pending, event StreamEvent) (StreamEvent, bool) {
+	switch p := pending.(type) {
+	case EventToken:
+		if e, ok := event.(EventToken); ok {
+			return NewEventToken(p.Content + e.Content), true
+		}
+	case EventThinking:
+		if e, ok := event.(EventThinking); ok {
+			return NewEventThinking(p.Content + e.Content), true
+		}
+	case EventToolCallToken:
+		if e, ok := event.(EventToolCallToken); ok && e.CallID == p.CallID {
+			return NewEventToolCallToken(p.CallID, p.Name, p.Content+e.Content), true
+		}
+	}
+	return nil, false
+}
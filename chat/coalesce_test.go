@@ -0,0 +1,133 @@
+package chat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func closedStream(events ...StreamEvent) <-chan StreamEvent {
+	ch := make(chan StreamEvent, len(events))
+	for _, event := range events {
+		ch <- event
+	}
+	close(ch)
+	return ch
+}
+
+func collect(events <-chan StreamEvent) []StreamEvent {
+	var result []StreamEvent
+	for event := range events {
+		result = append(result, event)
+	}
+	return result
+}
+
+func TestCoalesce_PreservesOrder(t *testing.T) {
+	events := closedStream(
+		NewEventCompletionStart(),
+		NewEventThinking("let "),
+		NewEventThinking("me think"),
+		NewEventToken("Hel"),
+		NewEventToken("lo"),
+		NewEventToolCallToken("call-1", "tool", `{"a"`),
+		NewEventToolCallToken("call-1", "tool", `:1}`),
+		NewEventToolCallToken("call-2", "tool", `{}`),
+		NewEventToken("!"),
+		NewEventCompletionEnded(nil),
+	)
+
+	result := collect(Coalesce(context.Background(), events, CoalesceOptions{MaxBytes: 1024}))
+
+	assert.Equal(t, []StreamEvent{
+		NewEventCompletionStart(),
+		NewEventThinking("let me think"),
+		NewEventToken("Hello"),
+		NewEventToolCallToken("call-1", "tool", `{"a":1}`),
+		NewEventToolCallToken("call-2", "tool", `{}`),
+		NewEventToken("!"),
+		NewEventCompletionEnded(nil),
+	}, result)
+}
+
+func TestCoalesce_MaxBytes(t *testing.T) {
+	events := closedStream(
+		NewEventToken("a"),
+		NewEventToken("b"),
+		NewEventToken("c"),
+		NewEventToken("def"),
+	)
+
+	result := collect(Coalesce(context.Background(), events, CoalesceOptions{MaxBytes: 2}))
+
+	assert.Equal(t, []StreamEvent{
+		NewEventToken("ab"),
+		NewEventToken("cdef"),
+	}, result)
+}
+
+func TestCoalesce_Window(t *testing.T) {
+	events := make(chan StreamEvent)
+	out := Coalesce(context.Background(), events, CoalesceOptions{Window: 20 * time.Millisecond})
+
+	events <- NewEventToken("a")
+	select {
+	case event := <-out:
+		assert.Equal(t, NewEventToken("a"), event, "tokens are delivered once the window passed")
+	case <-time.After(time.Second):
+		t.Fatal("token was not delivered after the window")
+	}
+
+	events <- NewEventToken("b")
+	events <- NewEventToken("c")
+	close(events)
+
+	assert.Equal(t, []StreamEvent{NewEventToken("bc")}, collect(out))
+}
+
+func TestCoalesce_Disabled(t *testing.T) {
+	events := closedStream(NewEventToken("a"))
+	assert.Equal(t, events, Coalesce(context.Background(), events, CoalesceOptions{}))
+}
+
+func TestCoalesce_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan StreamEvent)
+	out := Coalesce(ctx, events, CoalesceOptions{Window: time.Hour})
+
+	events <- NewEventToken("a")
+	cancel()
+	close(events)
+
+	select {
+	case <-out:
+	case <-time.After(time.Second):
+		t.Fatal("output was not closed after cancellation")
+	}
+}
+
+func TestSession_TokenCoalescing(t *testing.T) {
+	c := &Chat{
+		Messages:        NewMessages(),
+		Tools:           tools.NewTools(),
+		TokenCoalescing: CoalesceOptions{Window: time.Second, MaxBytes: 1024},
+	}
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToken("Hel"), NewEventToken("lo"), NewEventToken(" world")},
+	})
+
+	var tokens []StreamEvent
+	for event := range c.Session(context.Background(), mockClient) {
+		if _, ok := event.(EventToken); ok {
+			tokens = append(tokens, event)
+		}
+	}
+
+	require.Len(t, tokens, 1)
+	assert.Equal(t, NewEventToken("Hello world"), tokens[0])
+	assert.Equal(t, []StreamEvent{NewEventAssistantMessage("Hello world")}, withoutMetadata(c.Messages.Snapshot()))
+}
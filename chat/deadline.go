@@ -0,0 +1,72 @@
+package chat
+
+import "time"
+
+const DefaultExpiredToolMessage = "Tool call approval timed out"
+
+// TimeoutAction is applied to a tool call whose approval expired
+type TimeoutAction string
+
+const (
+	// TimeoutDeny declines the call with Chat.ExpiredToolMessage
+	TimeoutDeny TimeoutAction = "deny"
+	// TimeoutApprove executes the call. Calls of tools added with tools.WithRequiresApproval are denied instead
+	TimeoutApprove TimeoutAction = "approve"
+	// TimeoutEndSession ends the session, the call stays unanswered in the history
+	TimeoutEndSession TimeoutAction = "end_session"
+)
+
+// approvalTimeout returns the approval timeout of the call, 0 if the call can wait forever
+func (c *Chat) approvalTimeout(call EventToolCall) time.Duration {
+	if tool, ok := c.Tools.Get(call.Name); ok && tool.ApprovalTimeout() > 0 {
+		return tool.ApprovalTimeout()
+	}
+	return c.ApprovalTimeout
+}
+
+// expiredVerdict returns the verdict applied to a call whose approval expired
+func (c *Chat) expiredVerdict(call EventToolCall) (TimeoutAction, Verdict) {
+	action := c.ApprovalTimeoutAction
+	// only the user can approve such tools, not the lack of an answer
+	if tool, ok := c.Tools.Get(call.Name); ok && tool.RequiresApproval() && action == TimeoutApprove {
+		action = TimeoutDeny
+	}
+
+	switch action {
+	case TimeoutApprove:
+		return TimeoutApprove, Verdict{Accepted: true}
+	case TimeoutEndSession:
+		return TimeoutEndSession, Verdict{Accepted: false, endSession: true}
+	default:
+		msg := c.ExpiredToolMessage
+		if msg == "" {
+			msg = DefaultExpiredToolMessage
+		}
+		return TimeoutDeny, Verdict{Accepted: false, Reason: msg}
+	}
+}
+
+// watchApproval resolves the call with the timeout action if nobody resolves it in time
+func (s *sessionState) watchApproval(call EventToolCall) {
+	timeout := s.chat.approvalTimeout(call)
+	if timeout <= 0 {
+		return
+	}
+
+	timer := time.AfterFunc(timeout, func() {
+		if !call.claim() {
+			return
+		}
+		action, verdict := s.chat.expiredVerdict(call)
+		s.send(NewEventApprovalExpired(call.CallID, action))
+		call.deliver(verdict)
+	})
+	s.timers = append(s.timers, timer)
+}
+
+func (s *sessionState) stopTimers() {
+	for _, timer := range s.timers {
+		timer.Stop()
+	}
+	s.timers = s.timers[:0]
+}
@@ -0,0 +1,188 @@
+package chat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x2d7/interlude/chat/tools"
+	"go.uber.org/goleak"
+)
+
+// runIgnoringApprovals runs the session without resolving any tool call
+func runIgnoringApprovals(c *Chat, rounds [][]StreamEvent) []StreamEvent {
+	var received []StreamEvent
+	for event := range c.Session(context.Background(), NewMultiRoundMockClient(rounds)) {
+		received = append(received, event)
+	}
+	return received
+}
+
+func expiredEvents(events []StreamEvent) []EventApprovalExpired {
+	var expired []EventApprovalExpired
+	for _, event := range events {
+		if e, ok := event.(EventApprovalExpired); ok {
+			expired = append(expired, e)
+		}
+	}
+	return expired
+}
+
+func TestSession_ApprovalTimeout_Deny(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	c, _ := newToolChat(t, "test-tool", "executed")
+	c.ApprovalTimeout = 10 * time.Millisecond
+
+	received := runIgnoringApprovals(c, [][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{},
+	})
+
+	assert.Equal(t, []EventApprovalExpired{{CallID: "call-1", Action: TimeoutDeny}}, expiredEvents(received))
+	assert.Contains(t, received, EventToolCallResolved{CallID: "call-1", Accepted: false, Reason: DefaultExpiredToolMessage})
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", DefaultExpiredToolMessage, false)},
+		toolMessagesOf(c.Messages.Snapshot()))
+}
+
+func TestSession_ApprovalTimeout_Approve(t *testing.T) {
+	c, _ := newToolChat(t, "test-tool", "executed")
+	c.ApprovalTimeout = 10 * time.Millisecond
+	c.ApprovalTimeoutAction = TimeoutApprove
+
+	received := runIgnoringApprovals(c, [][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{},
+	})
+
+	assert.Equal(t, []EventApprovalExpired{{CallID: "call-1", Action: TimeoutApprove}}, expiredEvents(received))
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", "executed", true)},
+		toolMessagesOf(c.Messages.Snapshot()))
+}
+
+func TestSession_ApprovalTimeout_ApproveRequiresApproval(t *testing.T) {
+	c, execCount := newToolChat(t, "test-tool", "executed", tools.WithRequiresApproval())
+	c.ApprovalTimeout = 10 * time.Millisecond
+	c.ApprovalTimeoutAction = TimeoutApprove
+
+	received := runIgnoringApprovals(c, [][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{},
+	})
+
+	assert.Equal(t, []EventApprovalExpired{{CallID: "call-1", Action: TimeoutDeny}}, expiredEvents(received))
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", DefaultExpiredToolMessage, false)},
+		toolMessagesOf(c.Messages.Snapshot()))
+	assert.Zero(t, execCount.Load(), "the tool must not run without an explicit approval")
+}
+
+func TestSession_ApprovalTimeout_EndSession(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	c, _ := newToolChat(t, "test-tool", "executed")
+	c.ApprovalTimeout = 10 * time.Millisecond
+	c.ApprovalTimeoutAction = TimeoutEndSession
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{NewEventToken("must not be reached")},
+	})
+
+	var received []StreamEvent
+	for event := range c.Session(context.Background(), mockClient) {
+		received = append(received, event)
+	}
+
+	assert.Equal(t, []EventApprovalExpired{{CallID: "call-1", Action: TimeoutEndSession}}, expiredEvents(received))
+	assert.IsType(t, EventApprovalExpired{}, received[len(received)-1])
+	assert.Empty(t, toolMessagesOf(c.Messages.Snapshot()))
+}
+
+func TestSession_ApprovalTimeout_EndSessionKeepsFinishedResults(t *testing.T) {
+	c, _ := newToolChat(t, "test-tool", "executed")
+	fast, err := tools.NewTool("fast-tool", "", func(input map[string]string) (string, error) {
+		return "fast", nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Tools.Add(fast))
+	c.ApprovalPolicy = AllowList("fast-tool")
+	c.ApprovalTimeout = 20 * time.Millisecond
+	c.ApprovalTimeoutAction = TimeoutEndSession
+
+	received := runIgnoringApprovals(c, [][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "test-tool", `{}`),
+			NewEventToolCall("call-2", "fast-tool", `{}`),
+		},
+		{NewEventToken("must not be reached")},
+	})
+
+	// the result of call-2 was sent while it waited for call-1, so it must be in the history
+	assert.Contains(t, withoutMetadata(received), StreamEvent(NewEventToolMessage("call-2", "fast", true)))
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-2", "fast", true)},
+		toolMessagesOf(c.Messages.Snapshot()))
+
+	pending := c.Messages.PendingToolCalls()
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "call-1", pending[0].CallID)
+	}
+}
+
+func TestSession_ApprovalTimeout_ResolvedInTime(t *testing.T) {
+	c, _ := newToolChat(t, "test-tool", "executed")
+	c.ApprovalTimeout = 50 * time.Millisecond
+
+	received := collectResolving(c.Session(context.Background(), NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{},
+	})))
+
+	// wait for the timer which must already be stopped
+	time.Sleep(80 * time.Millisecond)
+
+	assert.Empty(t, expiredEvents(received))
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", "executed", true)},
+		toolMessagesOf(c.Messages.Snapshot()))
+}
+
+func TestSession_ApprovalTimeout_PerTool(t *testing.T) {
+	c, _ := newToolChat(t, "test-tool", "executed", tools.WithApprovalTimeout(10*time.Millisecond))
+	c.ExpiredToolMessage = "nobody answered"
+
+	received := runIgnoringApprovals(c, [][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{},
+	})
+
+	assert.Len(t, expiredEvents(received), 1)
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", "nobody answered", false)},
+		toolMessagesOf(c.Messages.Snapshot()))
+}
+
+func TestSession_ResolveAfterSessionEnded(t *testing.T) {
+	c, _ := newToolChat(t, "test-tool", "executed")
+	c.MaxRounds = 1
+	c.FinalAnswerOnLimit = true
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{NewEventToolCall("call-2", "test-tool", `{}`)},
+	})
+
+	var late []EventToolCall
+	for event := range c.Session(context.Background(), mockClient) {
+		if tc, ok := event.(EventToolCall); ok {
+			if tc.CallID == "call-1" {
+				tc.Resolve(true)
+				continue
+			}
+			late = append(late, tc)
+		}
+	}
+
+	// resolving a call of a finished session must not panic
+	for _, tc := range late {
+		assert.NotPanics(t, func() { tc.Resolve(true) })
+	}
+}
@@ -7,4 +7,15 @@ var (
 	ErrNilStreaming             = errors.New("streaming object is nil")
 	ErrAssistantMessageNotFound = errors.New("assistant message not found")
 	ErrAlreadyResolved          = errors.New("tool call already resolved")
+	ErrMaxRoundsReached         = errors.New("maximum amount of completion rounds reached")
+	ErrToolCallLoop             = errors.New("repeated identical tool calls detected")
+	ErrSessionEnded             = errors.New("session ended")
+	ErrToolCallNotFound         = errors.New("tool call not found")
+	ErrToolCallDetached         = errors.New("tool call is not bound to a session")
+	ErrToolChoiceUnsupported    = errors.New("client can't force a tool call")
+	ErrExtractionFailed         = errors.New("extraction failed")
+	ErrBranchNotFound           = errors.New("branch not found")
+	ErrBranchExists             = errors.New("branch already exists")
+	ErrActiveBranch             = errors.New("branch is active")
+	ErrUserMessageNotFound      = errors.New("user message not found")
 )
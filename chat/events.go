@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"sync/atomic"
+
+	"github.com/x2d7/interlude/chat/tools"
 )
 
 // eventType represents the type of event
@@ -19,10 +21,15 @@ const (
 	eventRefusal         eventType = "refusal"
 	eventCompletionStart eventType = "completion_start"
 	eventCompletionEnded eventType = "completion_ended"
+	eventLimitReached    eventType = "limit_reached"
+	eventUsage           eventType = "usage"
+	eventInterrupted     eventType = "interrupted"
+	eventGeneration      eventType = "generation"
 
 	// events produced by consumer
 
 	eventToolCallResolved eventType = "tool_call_resolved"
+	eventApprovalExpired  eventType = "approval_expired"
 	eventUserMessage      eventType = "user_message"
 	eventAssistantMessage eventType = "assistant_message"
 	eventReasoningMessage eventType = "reasoning_message"
@@ -34,7 +41,6 @@ const (
 	eventError eventType = "error"
 )
 
-// TODO: Добавить в будущем возможность класть метадату в события (учет стоимости, айди генерации)
 // TODO: Добавить возмжность добавлять Name к событиям сообщений (четкое разделение отправителей)
 
 // EventBase is a base type for simple event types
@@ -63,47 +69,266 @@ func NewEventCompletionEnded(toolCalls []EventToolCall) EventCompletionEnded {
 	return EventCompletionEnded{ToolCalls: toolCalls}
 }
 
+// Usage is the token usage of completions
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	ReasoningTokens  int64 `json:"reasoning_tokens,omitempty"`
+	CachedTokens     int64 `json:"cached_tokens,omitempty"`
+}
+
+// Add returns the sum of both usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		ReasoningTokens:  u.ReasoningTokens + other.ReasoningTokens,
+		CachedTokens:     u.CachedTokens + other.CachedTokens,
+	}
+}
+
+// EventUsage reports the token usage of the completion.
+// Sent by connectors which receive usage from the provider, it's not stored in the chat
+type EventUsage struct {
+	Usage
+}
+
+func (e EventUsage) getType() eventType { return eventUsage }
+
+func NewEventUsage(usage Usage) EventUsage {
+	return EventUsage{Usage: usage}
+}
+
+// EventGeneration describes the completion, as reported by the provider.
+// Sent by connectors, the details are added to the Metadata of the messages generated by the completion
+type EventGeneration struct {
+	Model        string `json:"model,omitempty"`
+	GenerationID string `json:"generation_id,omitempty"`
+}
+
+func (e EventGeneration) getType() eventType { return eventGeneration }
+
+func NewEventGeneration(model, generationID string) EventGeneration {
+	return EventGeneration{Model: model, GenerationID: generationID}
+}
+
+// EventInterrupted is sent when the session ends because it was interrupted (see SessionHandle.Interrupt)
+type EventInterrupted struct{}
+
+func (e EventInterrupted) getType() eventType { return eventInterrupted }
+
+func NewEventInterrupted() EventInterrupted {
+	return EventInterrupted{}
+}
+
+// PartialReason marks a message which was committed before the completion finished
+type PartialReason string
+
+const (
+	// PartialInterrupted marks the output of an interrupted completion
+	PartialInterrupted PartialReason = "interrupted"
+	// PartialError marks the output of a completion whose stream failed
+	PartialError PartialReason = "error"
+)
+
+// LimitKind describes which session limit was reached
+type LimitKind string
+
+const (
+	LimitMaxRounds    LimitKind = "max_rounds"
+	LimitToolCallLoop LimitKind = "tool_call_loop"
+)
+
+// EventLimitReached is sent when the session stops because of one of the Chat limits
+// (see Chat.MaxRounds and Chat.MaxIdenticalToolCalls)
+type EventLimitReached struct {
+	Limit  LimitKind `json:"limit"`
+	Rounds int       `json:"rounds"`
+}
+
+func (e EventLimitReached) getType() eventType { return eventLimitReached }
+
+// Err returns the error matching the reached limit
+func (e EventLimitReached) Err() error {
+	switch e.Limit {
+	case LimitToolCallLoop:
+		return ErrToolCallLoop
+	default:
+		return ErrMaxRoundsReached
+	}
+}
+
+func NewEventLimitReached(limit LimitKind, rounds int) EventLimitReached {
+	return EventLimitReached{Limit: limit, Rounds: rounds}
+}
+
 // EventToolCall represents a tool call event
 type EventToolCall struct {
 	EventBase
 	CallID string `json:"call_id"`
 	Name   string `json:"name"`
 
+	// approval details declared on tool registration (see tools.WithRiskLevel, tools.WithApprovalPrompt)
+
+	Risk   tools.RiskLevel `json:"risk,omitempty"`
+	Prompt string          `json:"prompt,omitempty"`
+
+	Meta Metadata `json:"meta,omitzero"`
+
 	approval   *ApproveWaiter
-	answered   *atomic.Bool
-	onResolved func(callID string, accepted bool)
+	resolution *resolution
+	tools      *tools.Tools
+	// detached is set on calls restored from JSON until they are bound to a session (see Chat.Bind)
+	detached bool
+}
+
+// toolCallFields is EventToolCall without its JSON methods
+type toolCallFields EventToolCall
+
+// toolCallJSON is the serialized form of EventToolCall, it keeps the resolution state of the call
+type toolCallJSON struct {
+	toolCallFields
+	Resolved bool `json:"resolved,omitempty"`
+}
+
+func (e EventToolCall) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toolCallJSON{
+		toolCallFields: toolCallFields(e),
+		Resolved:       e.resolution != nil && e.resolution.answered.Load(),
+	})
+}
+
+// UnmarshalJSON restores the call detached from any session: it reports the serialized resolution state,
+// but it can't be resolved until it's bound to the session awaiting it with Chat.Bind
+func (e *EventToolCall) UnmarshalJSON(data []byte) error {
+	var v toolCallJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*e = EventToolCall(v.toolCallFields)
+	e.resolution = newResolution()
+	if v.Resolved {
+		e.claim()
+	}
+	e.detached = true
+	return nil
+}
+
+// resolution is the resolution state shared by all copies of a tool call
+type resolution struct {
+	answered atomic.Bool
+	done     chan struct{} // closed once the call is resolved
+}
+
+func newResolution() *resolution {
+	return &resolution{done: make(chan struct{})}
+}
+
+// Resolved reports whether a verdict was already submitted for the call (by the consumer or by a policy)
+func (e *EventToolCall) Resolved() bool {
+	return e.resolution == nil || e.resolution.answered.Load()
 }
 
 func (e *EventToolCall) Resolve(accept bool) error {
-	if e.answered == nil || !e.answered.CompareAndSwap(false, true) {
-		return ErrAlreadyResolved
+	return e.resolve(Verdict{Accepted: accept})
+}
+
+// ResolveWithArguments approves the call, replacing the arguments generated by the model.
+// The arguments are validated against the schema of the tool; the call stays unresolved if they are invalid.
+// The edited arguments are executed and stored in the chat history instead of the original ones
+func (e *EventToolCall) ResolveWithArguments(arguments string) error {
+	return e.ResolveVerdict(Verdict{Accepted: true, Arguments: arguments})
+}
+
+// Decline rejects the call, sending the reason back to the model instead of Chat.DeclinedToolMessage
+func (e *EventToolCall) Decline(reason string) error {
+	return e.resolve(Verdict{Accepted: false, Reason: reason})
+}
+
+// ResolveWithResult skips the execution of the call and uses the given result as the tool output,
+// e.g. for results produced manually or mocked
+func (e *EventToolCall) ResolveWithResult(result string) error {
+	return e.resolve(Verdict{Accepted: true, Supplied: true, Result: result})
+}
+
+// ResolveVerdict resolves the call with the given verdict.
+// Edited arguments of an accepted verdict are validated like in ResolveWithArguments
+func (e *EventToolCall) ResolveVerdict(verdict Verdict) error {
+	if verdict.Accepted && verdict.Arguments != "" {
+		if err := e.validateArguments(verdict.Arguments); err != nil {
+			return err
+		}
 	}
-	if e.approval == nil {
-		return nil
+	return e.resolve(Verdict{
+		Accepted:  verdict.Accepted,
+		Reason:    verdict.Reason,
+		Arguments: verdict.Arguments,
+		Supplied:  verdict.Supplied,
+		Result:    verdict.Result,
+	})
+}
+
+func (e *EventToolCall) validateArguments(arguments string) error {
+	if e.tools != nil {
+		return e.tools.Validate(e.Name, arguments)
 	}
-	if e.onResolved != nil {
-		e.onResolved(e.CallID, accept)
+	if !json.Valid([]byte(arguments)) {
+		return tools.ErrInvalidArguments
 	}
-	e.approval.Resolve(Verdict{Accepted: accept, call: *e})
 	return nil
 }
 
+func (e *EventToolCall) resolve(verdict Verdict) error {
+	if e.detached {
+		return ErrToolCallDetached
+	}
+	if !e.claim() {
+		return ErrAlreadyResolved
+	}
+	e.deliver(verdict)
+	return nil
+}
+
+// claim marks the call as resolved, it returns false if the call was already resolved
+func (e *EventToolCall) claim() bool {
+	if e.resolution == nil || !e.resolution.answered.CompareAndSwap(false, true) {
+		return false
+	}
+	close(e.resolution.done)
+	return true
+}
+
+// deliver passes the verdict of a claimed call to the approval waiter
+func (e *EventToolCall) deliver(verdict Verdict) {
+	if e.approval == nil {
+		return
+	}
+	verdict.call = *e
+	e.approval.Resolve(verdict)
+}
+
 func (e EventToolCall) getType() eventType { return eventToolCall }
 
 // NewEventToolCall creates a new EventToolCall
 func NewEventToolCall(callID, name string, arguments string) EventToolCall {
 	return EventToolCall{
-		EventBase: EventBase{Content: arguments},
-		CallID:    callID,
-		Name:      name,
-		answered:  &atomic.Bool{},
+		EventBase:  EventBase{Content: arguments},
+		CallID:     callID,
+		Name:       name,
+		resolution: newResolution(),
 	}
 }
 
-// EventToolCallResolved spawns when tool call is resolved by the user
+// EventToolCallResolved spawns when the session takes the verdict of a tool call,
+// which happens after EventCompletionEnded for calls resolved while the completion is streamed
 type EventToolCallResolved struct {
-	CallID   string `json:"call_id"`
-	Accepted bool   `json:"accepted"`
+	CallID    string `json:"call_id"`
+	Accepted  bool   `json:"accepted"`
+	Reason    string `json:"reason,omitempty"`
+	Arguments string `json:"arguments,omitempty"` // edited arguments, see EventToolCall.ResolveWithArguments
+	Supplied  bool   `json:"supplied,omitempty"`  // result supplied by the approver, see EventToolCall.ResolveWithResult
 }
 
 func (e EventToolCallResolved) getType() eventType { return eventToolCallResolved }
@@ -112,6 +337,19 @@ func NewEventToolCallResolved(callID string, accepted bool) EventToolCallResolve
 	return EventToolCallResolved{CallID: callID, Accepted: accepted}
 }
 
+// EventApprovalExpired spawns when nobody resolved the tool call in time (see Chat.ApprovalTimeout).
+// It's followed by EventToolCallResolved unless the action ends the session
+type EventApprovalExpired struct {
+	CallID string        `json:"call_id"`
+	Action TimeoutAction `json:"action"`
+}
+
+func (e EventApprovalExpired) getType() eventType { return eventApprovalExpired }
+
+func NewEventApprovalExpired(callID string, action TimeoutAction) EventApprovalExpired {
+	return EventApprovalExpired{CallID: callID, Action: action}
+}
+
 // EventToolCallToken represents a tool call token event
 // Used for streaming. Not supported by some providers
 type EventToolCallToken struct {
@@ -185,6 +423,7 @@ func NewEventThinking(content string) EventThinking {
 // EventUserMessage represents a user message event
 type EventUserMessage struct {
 	EventBase
+	Meta Metadata `json:"meta,omitzero"`
 }
 
 func (e EventUserMessage) getType() eventType { return eventUserMessage }
@@ -197,6 +436,9 @@ func NewEventUserMessage(content string) EventUserMessage {
 // EventAssistantMessage represents an assistant message event
 type EventAssistantMessage struct {
 	EventBase
+	// Partial is set when the message was cut off before the completion finished
+	Partial PartialReason `json:"partial,omitempty"`
+	Meta    Metadata      `json:"meta,omitzero"`
 }
 
 func (e EventAssistantMessage) getType() eventType { return eventAssistantMessage }
@@ -209,6 +451,9 @@ func NewEventAssistantMessage(content string) EventAssistantMessage {
 // EventReasoningMessage represents a reasoning message event (accumulated thinking content)
 type EventReasoningMessage struct {
 	EventBase
+	// Partial is set when the reasoning was cut off before the completion finished
+	Partial PartialReason `json:"partial,omitempty"`
+	Meta    Metadata      `json:"meta,omitzero"`
 }
 
 func (e EventReasoningMessage) getType() eventType { return eventReasoningMessage }
@@ -221,6 +466,7 @@ func NewEventReasoningMessage(content string) EventReasoningMessage {
 // EventSystemMessage represents a system message event
 type EventSystemMessage struct {
 	EventBase
+	Meta Metadata `json:"meta,omitzero"`
 }
 
 func (e EventSystemMessage) getType() eventType { return eventSystemMessage }
@@ -235,6 +481,9 @@ type EventToolMessage struct {
 	EventBase
 	CallID  string `json:"call_id"`
 	Success bool   `json:"success"`
+	// Supplied is set when the result was supplied by the approver instead of the tool execution
+	Supplied bool     `json:"supplied,omitempty"`
+	Meta     Metadata `json:"meta,omitzero"`
 }
 
 func (e EventToolMessage) getType() eventType { return eventToolMessage }
@@ -247,6 +496,7 @@ func NewEventToolMessage(callID, content string, success bool) EventToolMessage
 // EventRefusal represents a refusal event
 type EventRefusal struct {
 	EventBase
+	Meta Metadata `json:"meta,omitzero"`
 }
 
 func (e EventRefusal) getType() eventType { return eventRefusal }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/x2d7/interlude/chat/tools"
 )
 
 func TestEventToolCall_Resolve_ReturnsErrorOnDuplicate(t *testing.T) {
@@ -27,3 +28,40 @@ func TestEventToolCall_Resolve_CopiesShareResolution(t *testing.T) {
 	err = copy2.Resolve(true)
 	assert.ErrorIs(t, err, ErrAlreadyResolved)
 }
+
+func TestEventToolCall_ResolveWithArguments_Validation(t *testing.T) {
+	toolList := tools.NewTools()
+	tool, err := tools.NewTool("read", "", func(input struct {
+		Path string `json:"path"`
+	}) (string, error) {
+		return input.Path, nil
+	})
+	assert.NoError(t, err)
+	toolList.Add(tool)
+
+	tc := NewEventToolCall("call-1", "read", `{"path": "wrong.txt"}`)
+	tc.tools = toolList
+
+	err = tc.ResolveWithArguments(`{"file": "right.txt"}`)
+	assert.ErrorIs(t, err, tools.ErrInvalidArguments)
+	assert.False(t, tc.Resolved(), "invalid arguments must leave the call unresolved")
+
+	assert.NoError(t, tc.ResolveWithArguments(`{"path": "right.txt"}`))
+	assert.ErrorIs(t, tc.ResolveWithArguments(`{"path": "again.txt"}`), ErrAlreadyResolved)
+}
+
+func TestEventToolCall_ResolveWithArguments_NoTools(t *testing.T) {
+	tc := NewEventToolCall("call-1", "read", `{}`)
+
+	assert.ErrorIs(t, tc.ResolveWithArguments(`{"path": `), tools.ErrInvalidArguments)
+	assert.NoError(t, tc.ResolveWithArguments(`{"path": "a"}`))
+}
+
+func TestEventToolCall_DeclineAndResolveWithResult_ShareResolution(t *testing.T) {
+	tc := NewEventToolCall("call-1", "test-tool", `{}`)
+	copy1 := tc
+
+	assert.NoError(t, tc.Decline("no"))
+	assert.ErrorIs(t, copy1.ResolveWithResult("result"), ErrAlreadyResolved)
+	assert.ErrorIs(t, copy1.Decline("again"), ErrAlreadyResolved)
+}
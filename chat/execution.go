@@ -0,0 +1,174 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// toolMessage executes the call of an accepted verdict or builds the supplied or declined tool message
+func (c *Chat) toolMessage(ctx context.Context, verdict Verdict) EventToolMessage {
+	call := verdict.call
+
+	if verdict.Accepted && verdict.Supplied {
+		toolMessage := NewEventToolMessage(call.CallID, verdict.Result, true)
+		toolMessage.Supplied = true
+		return toolMessage
+	}
+
+	if verdict.Accepted {
+		arguments := call.Content
+		if verdict.Arguments != "" {
+			arguments = verdict.Arguments
+		}
+		call.Content = arguments
+
+		call, err := c.beforeToolCall(ctx, call)
+		if err != nil {
+			return NewEventToolMessage(call.CallID, err.Error(), false)
+		}
+
+		started := time.Now()
+		callResult, success := c.Tools.Execute(ctx, call.Name, call.Content)
+		toolMessage := NewEventToolMessage(call.CallID, callResult, success)
+		toolMessage.Meta.Latency = time.Since(started)
+		return c.afterToolCall(ctx, call, toolMessage)
+	}
+
+	msg := verdict.Reason
+	if msg == "" {
+		msg = c.DeclinedToolMessage
+	}
+	if msg == "" {
+		msg = DefaultDeclinedToolMessage
+	}
+	return NewEventToolMessage(call.CallID, msg, false)
+}
+
+// cancelledToolMessage is the tool message of an accepted call which was not executed because the session ended
+func cancelledToolMessage(call EventToolCall, err error) EventToolMessage {
+	return NewEventToolMessage(call.CallID, fmt.Sprintf("error: tool %q cancelled: %v", call.Name, err), false)
+}
+
+// resolvedEvent describes the verdict of a resolved call
+func resolvedEvent(verdict Verdict) EventToolCallResolved {
+	return EventToolCallResolved{
+		CallID:    verdict.call.CallID,
+		Accepted:  verdict.Accepted,
+		Reason:    verdict.Reason,
+		Arguments: verdict.Arguments,
+		Supplied:  verdict.Supplied,
+	}
+}
+
+// toolResult is a tool message bound to the position of its call in the completion
+type toolResult struct {
+	index   int
+	message EventToolMessage
+}
+
+// executeTools executes accepted calls using at most Chat.ParallelToolCalls workers.
+//
+// Every tool message is sent as soon as it's ready, but it's added to the chat only after
+// the messages of all previous calls, so the history follows the order of the calls
+// regardless of the order of verdicts and executions. When the session ends early, the messages
+// which were sent are added anyway and the messages of the unanswered calls follow them on resume
+func (c *Chat) executeTools(ctx context.Context, state *sessionState, verdicts <-chan Verdict) (proceed bool) {
+	calls := state.toolCalls
+
+	positions := make(map[string]int, len(calls))
+	for i, call := range calls {
+		positions[call.CallID] = i
+	}
+
+	// buffered, so workers never block after the session stopped waiting for them
+	finished := make(chan toolResult, len(calls))
+	workers := make(chan struct{}, max(c.ParallelToolCalls, 1))
+
+	results := make([]*EventToolMessage, len(calls))
+	committed, received := 0, 0
+
+	// commitFinished adds the messages which were already sent but wait for a previous call.
+	// They're added in the order of the calls, the calls without a message stay unanswered in the history.
+	// It's called on every exit, so a result which was sent is never executed again by Chat.Resume
+	commitFinished := func() {
+		for _, result := range results[committed:] {
+			if result != nil {
+				c.AppendEvent(*result)
+			}
+		}
+		committed = len(results)
+	}
+	defer commitFinished()
+
+	for received < len(calls) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-state.interrupt:
+			// unanswered calls stay in the history, they can be answered later with Chat.Resume
+			commitFinished()
+			state.send(NewEventInterrupted())
+			return false
+		case verdict, ok := <-verdicts:
+			if !ok {
+				if ctx.Err() != nil {
+					return false
+				}
+				verdicts = nil
+				continue
+			}
+
+			// expired approval ended the session, unanswered calls stay in the history
+			if verdict.endSession {
+				return false
+			}
+
+			// sent by the session rather than by the resolver, which may be the consumer itself
+			if !state.send(resolvedEvent(verdict)) {
+				return false
+			}
+
+			// the model must see the arguments which were actually executed
+			if verdict.Accepted && verdict.Arguments != "" {
+				c.Messages.updateToolCallArguments(verdict.call.CallID, verdict.Arguments)
+			}
+
+			index := positions[verdict.call.CallID]
+			if !verdict.Accepted || verdict.Supplied {
+				finished <- toolResult{index: index, message: c.toolMessage(ctx, verdict)}
+				continue
+			}
+
+			go func() {
+				workers <- struct{}{}
+				defer func() { <-workers }()
+
+				// the session may have ended while the call was queued, it must not run anymore
+				if err := ctx.Err(); err != nil {
+					finished <- toolResult{index: index, message: cancelledToolMessage(verdict.call, err)}
+					return
+				}
+				finished <- toolResult{index: index, message: c.toolMessage(ctx, verdict)}
+			}()
+		case result := <-finished:
+			received++
+			// stamped before it's sent, so the consumer sees the stored message
+			message := stamp(result.message)
+			results[result.index] = &message
+
+			// sending tool message
+			if !state.send(message) {
+				return false
+			}
+
+			// adding tool messages to the chat as soon as all previous calls are done
+			for committed < len(results) && results[committed] != nil {
+				c.AppendEvent(*results[committed])
+				committed++
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,415 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+// newSleepingChat creates a chat with a tool sleeping for the given amount of milliseconds
+// and tracking the maximum amount of concurrent executions
+func newSleepingChat(t *testing.T, parallel int) (*Chat, *atomic.Int32) {
+	t.Helper()
+
+	var running, maxRunning atomic.Int32
+	c := &Chat{
+		Messages:          NewMessages(),
+		Tools:             tools.NewTools(),
+		ParallelToolCalls: parallel,
+	}
+	tool, err := tools.NewTool("sleep", "Sleeps", func(ms int) (string, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := maxRunning.Load()
+			if current <= old || maxRunning.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+		return fmt.Sprintf("slept %d", ms), nil
+	})
+	require.NoError(t, err)
+	c.Tools.Add(tool)
+
+	return c, &maxRunning
+}
+
+func toolMessagesOf(events []StreamEvent) []EventToolMessage {
+	var messages []EventToolMessage
+	for _, event := range events {
+		if tm, ok := event.(EventToolMessage); ok {
+			messages = append(messages, stripMetadata(tm))
+		}
+	}
+	return messages
+}
+
+func TestSession_ParallelToolCalls_KeepsCallOrder(t *testing.T) {
+	c, maxRunning := newSleepingChat(t, 2)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "sleep", `{"input": 60}`),
+			NewEventToolCall("call-2", "sleep", `{"input": 10}`),
+			NewEventToolCall("call-3", "sleep", `{"input": 30}`),
+			NewEventToolCall("call-4", "sleep", `{"input": 1}`),
+		},
+		{},
+	})
+
+	received := collectResolving(c.Session(context.Background(), mockClient))
+
+	assert.Equal(t, int32(2), maxRunning.Load())
+
+	history := toolMessagesOf(c.Messages.Snapshot())
+	require.Len(t, history, 4)
+	for i, tm := range history {
+		assert.Equal(t, fmt.Sprintf("call-%d", i+1), tm.CallID)
+		assert.True(t, tm.Success)
+	}
+	// results are streamed as soon as they are ready
+	assert.ElementsMatch(t, history, toolMessagesOf(received))
+}
+
+func TestSession_ParallelToolCalls_RunsConcurrently(t *testing.T) {
+	c, maxRunning := newSleepingChat(t, 8)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "sleep", `{"input": 100}`),
+			NewEventToolCall("call-2", "sleep", `{"input": 100}`),
+			NewEventToolCall("call-3", "sleep", `{"input": 100}`),
+		},
+		{},
+	})
+
+	start := time.Now()
+	collectResolving(c.Session(context.Background(), mockClient))
+
+	assert.Less(t, time.Since(start), 250*time.Millisecond)
+	assert.Equal(t, int32(3), maxRunning.Load())
+}
+
+func TestSession_ParallelToolCalls_Declined(t *testing.T) {
+	c, _ := newSleepingChat(t, 4)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "sleep", `{"input": 30}`),
+			NewEventToolCall("call-2", "sleep", `{"input": 1}`),
+		},
+		{},
+	})
+
+	for event := range c.Session(context.Background(), mockClient) {
+		if tc, ok := event.(EventToolCall); ok {
+			tc.Resolve(tc.CallID == "call-1")
+		}
+	}
+
+	history := toolMessagesOf(c.Messages.Snapshot())
+	require.Len(t, history, 2)
+	assert.Equal(t, NewEventToolMessage("call-1", "slept 30", true), history[0])
+	assert.Equal(t, NewEventToolMessage("call-2", DefaultDeclinedToolMessage, false), history[1])
+}
+
+func TestSession_ParallelToolCalls_ContextCancelled(t *testing.T) {
+	c, _ := newSleepingChat(t, 2)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "sleep", `{"input": 1}`)},
+		{},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for event := range c.Session(ctx, mockClient) {
+		if _, ok := event.(EventCompletionEnded); ok {
+			cancel()
+		}
+	}
+
+	assert.Empty(t, toolMessagesOf(c.Messages.Snapshot()))
+}
+
+func TestSession_ToolTimeout_ProducesFailedToolMessage(t *testing.T) {
+	c := &Chat{
+		Messages: NewMessages(),
+		Tools:    tools.NewTools(),
+	}
+	tool, err := tools.NewToolCtx("slow", "", func(ctx context.Context, input map[string]string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	require.NoError(t, err)
+	c.Tools.Add(tool, tools.WithTimeout(10*time.Millisecond))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "slow", `{}`)},
+		{},
+	})
+
+	collectResolving(c.Session(context.Background(), mockClient))
+
+	history := toolMessagesOf(c.Messages.Snapshot())
+	require.Len(t, history, 1)
+	assert.False(t, history[0].Success)
+	assert.Contains(t, history[0].Content, `tool "slow" timed out after 10ms`)
+}
+
+func TestSession_Cancel_StopsContextAwareTool(t *testing.T) {
+	c := &Chat{
+		Messages: NewMessages(),
+		Tools:    tools.NewTools(),
+	}
+	stopped := make(chan struct{})
+	tool, err := tools.NewToolCtx("wait", "", func(ctx context.Context, input map[string]string) (string, error) {
+		<-ctx.Done()
+		close(stopped)
+		return "", ctx.Err()
+	})
+	require.NoError(t, err)
+	c.Tools.Add(tool)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "wait", `{}`)},
+		{},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for event := range c.Session(ctx, mockClient) {
+		if tc, ok := event.(EventToolCall); ok {
+			tc.Resolve(true)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				cancel()
+			}()
+		}
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("tool was not cancelled with the session")
+	}
+}
+
+func TestSession_ToolMessages_OrderedByCallsNotByVerdicts(t *testing.T) {
+	c, _ := newSleepingChat(t, 0)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "sleep", `{"input": 1}`),
+			NewEventToolCall("call-2", "sleep", `{"input": 1}`),
+			NewEventToolCall("call-3", "sleep", `{"input": 1}`),
+		},
+		{},
+	})
+
+	var streamed []string
+	for event := range c.Session(context.Background(), mockClient) {
+		switch e := event.(type) {
+		case EventCompletionEnded:
+			if len(e.ToolCalls) == 0 {
+				continue
+			}
+			// resolve out of order, call-1 only after other results were streamed
+			e.ToolCalls[2].Resolve(true)
+			e.ToolCalls[1].Resolve(false)
+		case EventToolMessage:
+			streamed = append(streamed, e.CallID)
+			if len(streamed) == 2 {
+				for _, call := range c.Messages.Snapshot() {
+					if tc, ok := call.(EventToolCall); ok && tc.CallID == "call-1" {
+						tc.Resolve(true)
+					}
+				}
+			}
+		}
+	}
+
+	require.Len(t, streamed, 3)
+	assert.ElementsMatch(t, []string{"call-2", "call-3"}, streamed[:2])
+	assert.Equal(t, "call-1", streamed[2])
+
+	history := toolMessagesOf(c.Messages.Snapshot())
+	require.Len(t, history, 3)
+	assert.Equal(t, NewEventToolMessage("call-1", "slept 1", true), history[0])
+	assert.Equal(t, NewEventToolMessage("call-2", DefaultDeclinedToolMessage, false), history[1])
+	assert.Equal(t, NewEventToolMessage("call-3", "slept 1", true), history[2])
+}
+
+func TestSession_ResolveWithArguments(t *testing.T) {
+	c := &Chat{
+		Messages: NewMessages(),
+		Tools:    tools.NewTools(),
+	}
+	tool, err := tools.NewTool("read", "", func(input struct {
+		Path string `json:"path"`
+	}) (string, error) {
+		return "read " + input.Path, nil
+	})
+	require.NoError(t, err)
+	c.Tools.Add(tool)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "read", `{"path": "/wrong/path"}`)},
+		{},
+	})
+
+	var resolved []EventToolCallResolved
+	for event := range c.Session(context.Background(), mockClient) {
+		switch e := event.(type) {
+		case EventToolCall:
+			assert.Error(t, e.ResolveWithArguments(`{"path": 1}`))
+			assert.NoError(t, e.ResolveWithArguments(`{"path": "/right/path"}`))
+		case EventToolCallResolved:
+			resolved = append(resolved, e)
+		}
+	}
+
+	assert.Equal(t, []EventToolCallResolved{
+		{CallID: "call-1", Accepted: true, Arguments: `{"path": "/right/path"}`},
+	}, resolved)
+
+	messages := c.Messages.Snapshot()
+	var stored EventToolCall
+	for _, msg := range messages {
+		if tc, ok := msg.(EventToolCall); ok {
+			stored = tc
+		}
+	}
+	assert.Equal(t, `{"path": "/right/path"}`, stored.Content)
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", "read /right/path", true)}, toolMessagesOf(messages))
+}
+
+func TestSession_DeclineWithReasonAndSuppliedResult(t *testing.T) {
+	c, execCount := newToolChat(t, "test-tool", "result")
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "test-tool", `{"key": "1"}`),
+			NewEventToolCall("call-2", "test-tool", `{"key": "2"}`),
+		},
+		{},
+	})
+
+	var resolved []EventToolCallResolved
+	var streamed []EventToolMessage
+	for event := range c.Session(context.Background(), mockClient) {
+		switch e := event.(type) {
+		case EventToolCall:
+			if e.CallID == "call-1" {
+				assert.NoError(t, e.Decline("use the staging database instead"))
+			} else {
+				assert.NoError(t, e.ResolveWithResult("mocked result"))
+			}
+		case EventToolCallResolved:
+			resolved = append(resolved, e)
+		case EventToolMessage:
+			streamed = append(streamed, e)
+		}
+	}
+
+	assert.Equal(t, int32(0), execCount.Load(), "neither call must be executed")
+	// reported in the order the session takes the verdicts
+	assert.ElementsMatch(t, []EventToolCallResolved{
+		{CallID: "call-1", Accepted: false, Reason: "use the staging database instead"},
+		{CallID: "call-2", Accepted: true, Supplied: true},
+	}, resolved)
+
+	supplied := NewEventToolMessage("call-2", "mocked result", true)
+	supplied.Supplied = true
+	expected := []EventToolMessage{
+		NewEventToolMessage("call-1", "use the staging database instead", false),
+		supplied,
+	}
+	assert.Equal(t, expected, toolMessagesOf(c.Messages.Snapshot()))
+	assert.ElementsMatch(t, expected, withoutMetadata(streamed))
+}
+
+func TestSession_QueuedCallsAreNotExecutedAfterCancel(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	var started atomic.Int32
+	running := make(chan struct{}, 3)
+	tool, err := tools.NewToolCtx("block", "", func(ctx context.Context, input map[string]string) (string, error) {
+		started.Add(1)
+		running <- struct{}{}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(tool, tools.WithAutoApprove()))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{
+		NewEventToolCall("call-1", "block", `{}`),
+		NewEventToolCall("call-2", "block", `{}`),
+		NewEventToolCall("call-3", "block", `{}`),
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-running
+		// the other calls are queued behind the running one by now
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	for range c.Session(ctx, mockClient) {
+	}
+
+	// the queued calls would have started by now
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), started.Load(), "only the running call was started")
+}
+
+func TestSession_CancelKeepsSentResults(t *testing.T) {
+	c, fastCount := newToolChat(t, "fast", "fast result", tools.WithAutoApprove())
+	c.ParallelToolCalls = 2
+
+	// the first execution of the slow tool outlives the session
+	release := make(chan struct{})
+	defer close(release)
+	var slowCount atomic.Int32
+	slow, err := tools.NewTool("slow", "", func(input map[string]string) (string, error) {
+		if slowCount.Add(1) == 1 {
+			<-release
+		}
+		return "slow result", nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(slow, tools.WithAutoApprove()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{
+		NewEventToolCall("call-1", "slow", `{}`),
+		NewEventToolCall("call-2", "fast", `{}`),
+	}})
+	for event := range c.Session(ctx, mockClient) {
+		if message, ok := event.(EventToolMessage); ok && message.CallID == "call-2" {
+			cancel()
+		}
+	}
+
+	// the result of call-2 was sent, so only call-1 is answered by the resumed session
+	pending := c.Messages.PendingToolCalls()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "call-1", pending[0].CallID)
+
+	for range c.Resume(context.Background(), NewMultiRoundMockClient([][]StreamEvent{{}})) {
+	}
+
+	assert.Equal(t, int32(1), fastCount.Load(), "the sent result must not be executed again")
+	assert.Equal(t, int32(2), slowCount.Load())
+	assert.Empty(t, c.Messages.PendingToolCalls())
+}
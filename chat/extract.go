@@ -0,0 +1,174 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+const DefaultExtractToolName = "extract"
+
+const DefaultExtractRetries = 2
+
+// ToolChoiceClient is implemented by clients which can force the model to call a specific tool (see Extract)
+type ToolChoiceClient interface {
+	Client
+	// WithToolChoice returns a new client instance which forces the model to call the named tool.
+	// The original client must remain unchanged
+	WithToolChoice(name string) Client
+}
+
+type extractConfig struct {
+	name        string
+	description string
+	retries     int
+}
+
+// ExtractOption configures Extract
+type ExtractOption func(cfg *extractConfig)
+
+// WithExtractTool sets the name and the description of the synthetic tool (default: DefaultExtractToolName)
+func WithExtractTool(name, description string) ExtractOption {
+	return func(cfg *extractConfig) {
+		cfg.name = name
+		cfg.description = description
+	}
+}
+
+// WithExtractRetries sets the amount of completions repeated after a failed attempt (default: DefaultExtractRetries).
+// Negative values are treated as 0
+func WithExtractRetries(retries int) ExtractOption {
+	return func(cfg *extractConfig) {
+		cfg.retries = retries
+	}
+}
+
+// Extract asks the model for a value of T: it offers a synthetic tool whose input is T,
+// forces the model to call it and decodes the arguments of the call.
+// Arguments which don't match the schema of T, can't be decoded or fail the Validate() error method of T (or *T)
+// are sent back to the model as a failed tool result and the completion is repeated.
+// A completion without the call is repeated after a user message asking the model to call the tool.
+//
+// The client (as returned by SyncInput) must implement ToolChoiceClient, otherwise ErrToolChoiceUnsupported is returned.
+// Extraction runs on a copy of the chat history, the chat itself is not changed.
+// Errors of the stream are returned as they are, without retrying
+func Extract[T any](ctx context.Context, client Client, c *Chat, opts ...ExtractOption) (T, error) {
+	var zero T
+
+	cfg := extractConfig{name: DefaultExtractToolName, retries: DefaultExtractRetries}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.retries = max(cfg.retries, 0)
+
+	// the tool function is executed with the decoded arguments only to capture them
+	var value T
+	tool, err := tools.NewTool(cfg.name, cfg.description, func(input T) (string, error) {
+		value = input
+		return "", nil
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	work := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	if err := work.Tools.Add(tool); err != nil {
+		return zero, err
+	}
+	for _, event := range c.Messages.Snapshot() {
+		work.AppendEvent(event)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= cfg.retries; attempt++ {
+		synced, ok := client.SyncInput(work).(ToolChoiceClient)
+		if !ok {
+			return zero, ErrToolChoiceUnsupported
+		}
+
+		call, err := extractCall(ctx, work, synced.WithToolChoice(cfg.name), cfg.name)
+		if err != nil {
+			return zero, err
+		}
+		if call == nil {
+			lastErr = fmt.Errorf("the model did not call %q", cfg.name)
+			work.AppendEvent(NewEventUserMessage(fmt.Sprintf("Answer by calling %q", cfg.name)))
+			continue
+		}
+		work.AppendEvent(*call)
+
+		lastErr = decodeExtracted(ctx, work.Tools, *call)
+		if lastErr == nil {
+			lastErr = validateExtracted(&value)
+		}
+		if lastErr == nil {
+			return value, nil
+		}
+
+		work.AppendEvent(NewEventToolMessage(call.CallID, fmt.Sprintf(
+			"error: %v. Call %q again with corrected arguments", lastErr, cfg.name,
+		), false))
+	}
+
+	return zero, fmt.Errorf("%w after %d attempts: %w", ErrExtractionFailed, cfg.retries+1, lastErr)
+}
+
+// extractCall runs a completion and returns the first call of the named tool (nil if there's none)
+func extractCall(ctx context.Context, c *Chat, client Client, name string) (*EventToolCall, error) {
+	var calls []EventToolCall
+	var text strings.Builder
+
+	for event := range c.Complete(ctx, client) {
+		switch e := event.(type) {
+		case EventToken:
+			text.WriteString(e.Content)
+		case EventToolCall:
+			if e.CallID != "" {
+				calls = append(calls, e)
+			} else if len(calls) > 0 {
+				calls[len(calls)-1].Content += e.Content
+			}
+		case EventError:
+			return nil, e.Error
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if text.Len() > 0 {
+		c.AppendEvent(NewEventAssistantMessage(text.String()))
+	}
+	for i := range calls {
+		if calls[i].Name == name {
+			return &calls[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// validateExtracted runs the Validate() error method of the value, declared either on T or on *T
+func validateExtracted[T any](value *T) error {
+	type validator interface{ Validate() error }
+
+	if v, ok := any(*value).(validator); ok {
+		return v.Validate()
+	}
+	if v, ok := any(value).(validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// decodeExtracted validates the arguments of the call and executes the synthetic tool with them
+func decodeExtracted(ctx context.Context, toolset *tools.Tools, call EventToolCall) error {
+	if err := toolset.Validate(call.Name, call.Content); err != nil {
+		return err
+	}
+	if result, ok := toolset.Execute(ctx, call.Name, call.Content); !ok {
+		return fmt.Errorf("%w: %s", tools.ErrInvalidArguments, result)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+// toolChoiceClient records the forced tool choices of a MultiRoundMockClient
+type toolChoiceClient struct {
+	*MultiRoundMockClient
+	choices []string
+	synced  [][]StreamEvent
+}
+
+func (c *toolChoiceClient) SyncInput(chat *Chat) Client {
+	c.MultiRoundMockClient.SyncInput(chat)
+	c.synced = append(c.synced, chat.Messages.Snapshot())
+	return c
+}
+
+func (c *toolChoiceClient) WithToolChoice(name string) Client {
+	c.choices = append(c.choices, name)
+	return c
+}
+
+type person struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (p person) Validate() error {
+	if p.Age < 0 {
+		return errors.New("age must not be negative")
+	}
+	return nil
+}
+
+func TestExtract(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	c.AddMessage(SenderUser{}, "Alice is 30")
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", DefaultExtractToolName, `{"name":"Alice",`), NewEventToolCall("", "", `"age":30}`)},
+	})}
+
+	p, err := Extract[person](context.Background(), client, c)
+	require.NoError(t, err)
+
+	assert.Equal(t, person{Name: "Alice", Age: 30}, p)
+	assert.Equal(t, []string{DefaultExtractToolName}, client.choices)
+	assert.Len(t, c.Messages.Snapshot(), 1, "the chat is not changed")
+
+	synced := client.SyncedChat
+	_, ok := synced.Tools.Get(DefaultExtractToolName)
+	assert.True(t, ok, "the synthetic tool is offered to the model")
+}
+
+func TestExtract_RetriesWithError(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	c.AddMessage(SenderUser{}, "Bob is 40")
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", DefaultExtractToolName, `{"name":"Bob"}`)},
+		{NewEventToolCall("call-2", DefaultExtractToolName, `{"name":"Bob","age":-40}`)},
+		{NewEventToolCall("call-3", DefaultExtractToolName, `{"name":"Bob","age":40}`)},
+	})}
+
+	p, err := Extract[person](context.Background(), client, c)
+	require.NoError(t, err)
+	assert.Equal(t, person{Name: "Bob", Age: 40}, p)
+
+	// the errors are fed back to the model
+	require.Len(t, client.synced, 3)
+	history := client.synced[2]
+	require.Len(t, history, 5)
+	missing := history[2].(EventToolMessage)
+	assert.Equal(t, "call-1", missing.CallID)
+	assert.False(t, missing.Success)
+	assert.Contains(t, missing.Content, `missing required property "age"`)
+	assert.Contains(t, history[4].(EventToolMessage).Content, "age must not be negative")
+}
+
+func TestExtract_RetriesExhausted(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", DefaultExtractToolName, `not json`)},
+		{NewEventToken("I won't")},
+	})}
+
+	_, err := Extract[person](context.Background(), client, c, WithExtractRetries(1))
+	assert.ErrorIs(t, err, ErrExtractionFailed)
+	assert.Len(t, client.choices, 2)
+}
+
+func TestExtract_NonStructType(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tags", `{"input":["a","b"]}`)},
+	})}
+
+	tags, err := Extract[[]string](context.Background(), client, c, WithExtractTool("tags", "Lists the tags"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, tags)
+}
+
+func TestExtract_Unsupported(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	_, err := Extract[person](context.Background(), NewMultiRoundMockClient(nil), c)
+	assert.ErrorIs(t, err, ErrToolChoiceUnsupported)
+}
+
+func TestExtract_StreamError(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	streamErr := errors.New("stream failed")
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventError(streamErr)},
+	})}
+
+	_, err := Extract[person](context.Background(), client, c)
+	assert.ErrorIs(t, err, streamErr)
+	assert.Len(t, client.choices, 1, "stream errors are not retried")
+}
+
+type city struct {
+	Name string `json:"name"`
+}
+
+func (c *city) Validate() error {
+	if c.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	return nil
+}
+
+func TestExtract_PointerValidate(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", DefaultExtractToolName, `{"name":""}`)},
+		{NewEventToolCall("call-2", DefaultExtractToolName, `{"name":"Paris"}`)},
+	})}
+
+	got, err := Extract[city](context.Background(), client, c)
+	require.NoError(t, err)
+	assert.Equal(t, city{Name: "Paris"}, got)
+
+	require.Len(t, client.synced, 2)
+	assert.Contains(t, client.synced[1][1].(EventToolMessage).Content, "name must not be empty")
+}
+
+func TestExtract_RetryWithoutCall(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToken("Alice is thirty")},
+		{NewEventToolCall("call-1", DefaultExtractToolName, `{"name":"Alice","age":30}`)},
+	})}
+
+	p, err := Extract[person](context.Background(), client, c)
+	require.NoError(t, err)
+	assert.Equal(t, person{Name: "Alice", Age: 30}, p)
+
+	// the model is told to call the tool instead of getting the same history again
+	require.Len(t, client.synced, 2)
+	assert.Equal(t, []StreamEvent{
+		NewEventAssistantMessage("Alice is thirty"),
+		NewEventUserMessage(`Answer by calling "extract"`),
+	}, withoutMetadata(client.synced[1]))
+}
+
+func TestExtract_NegativeRetries(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	client := &toolChoiceClient{MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToken("I won't")},
+	})}
+
+	_, err := Extract[person](context.Background(), client, c, WithExtractRetries(-1))
+	assert.ErrorIs(t, err, ErrExtractionFailed)
+	assert.ErrorContains(t, err, `did not call "extract"`)
+	assert.Len(t, client.choices, 1, "the completion runs once")
+}
@@ -0,0 +1,173 @@
+package chat
+
+import (
+	"context"
+	"sync"
+)
+
+// SessionResult summarizes a finished session
+type SessionResult struct {
+	// Messages are the events added to Chat.Messages during the session
+	Messages []StreamEvent
+	// Rounds is the amount of completions started by the session
+	Rounds int
+	// Usage is the token usage of all completions, as reported by the connector with EventUsage
+	Usage Usage
+	// Err is the reason the session ended early: the first EventError, the error of EventLimitReached
+	// (unless Chat.FinalAnswerOnLimit is set) or the error of the cancelled context
+	Err error
+	// Interrupted is set when the session ended because of SessionHandle.Interrupt
+	Interrupted bool
+	// Steering are the messages enqueued with SessionHandle.Steer which were not added to the chat,
+	// because the session ended with unanswered tool calls
+	Steering []string
+}
+
+// SessionHandle controls a session started with Chat.Start.
+// The session only progresses while its events are consumed
+type SessionHandle struct {
+	chat   *Chat
+	events chan StreamEvent
+	cancel context.CancelFunc
+
+	interrupt     chan struct{}
+	interruptOnce sync.Once
+	steering      *steeringQueue
+
+	done   chan struct{}
+	mu     sync.Mutex
+	result SessionResult
+}
+
+// Start starts a session like Session and returns a handle to control it,
+// e.g. when the approvals arrive on a different request than the one streaming the events
+func (c *Chat) Start(ctx context.Context, client Client) *SessionHandle {
+	return c.start(ctx, client, sessionOptions{})
+}
+
+func (c *Chat) start(ctx context.Context, client Client, opts sessionOptions) *SessionHandle {
+	ctx, cancel := context.WithCancel(ctx)
+
+	h := &SessionHandle{
+		chat:   c,
+		events: make(chan StreamEvent, 16),
+		cancel: cancel,
+		done:   make(chan struct{}),
+
+		interrupt: make(chan struct{}),
+		steering:  newSteeringQueue(),
+	}
+	opts.interrupt = h.interrupt
+	opts.steering = h.steering
+
+	// messages added before the session are not part of the result
+	c.ensureDefaults()
+	offset := len(c.Messages.Snapshot())
+
+	events := c.session(ctx, client, opts)
+
+	go func() {
+		defer close(h.done)
+		defer cancel()
+		defer close(h.events)
+
+		forward := true
+		for event := range events {
+			h.observe(event)
+
+			// the consumer may stop reading after Cancel, the rest of the events is drained
+			if !forward {
+				continue
+			}
+			select {
+			case h.events <- event:
+			case <-ctx.Done():
+				forward = false
+			}
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if messages := c.Messages.Snapshot(); len(messages) > offset {
+			h.result.Messages = messages[offset:]
+		}
+		if h.result.Err == nil {
+			h.result.Err = ctx.Err()
+		}
+		h.result.Steering = h.steering.drain()
+	}()
+
+	return h
+}
+
+// observe updates the result of the session with the event
+func (h *SessionHandle) observe(event StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch e := event.(type) {
+	case EventCompletionStart:
+		h.result.Rounds++
+	case EventInterrupted:
+		h.result.Interrupted = true
+	case EventUsage:
+		h.result.Usage = h.result.Usage.Add(e.Usage)
+	case EventError:
+		if h.result.Err == nil {
+			h.result.Err = e.Error
+		}
+	case EventLimitReached:
+		if h.result.Err == nil && !h.chat.FinalAnswerOnLimit {
+			h.result.Err = e.Err()
+		}
+	}
+}
+
+// Events returns the event stream of the session, it's closed when the session ends
+func (h *SessionHandle) Events() <-chan StreamEvent {
+	return h.events
+}
+
+// Resolve resolves the tool call with the given ID awaited by the session (see EventToolCall.ResolveVerdict).
+// It returns ErrToolCallNotFound if the session doesn't await such call
+func (h *SessionHandle) Resolve(callID string, verdict Verdict) error {
+	call, ok := h.chat.ToolCall(callID)
+	if !ok {
+		return ErrToolCallNotFound
+	}
+	return call.ResolveVerdict(verdict)
+}
+
+// Cancel stops the session, the events which were not consumed yet are discarded
+func (h *SessionHandle) Cancel() {
+	h.cancel()
+}
+
+// Interrupt stops the current completion and ends the session. The assistant text and reasoning
+// streamed so far are added to the chat marked with PartialInterrupted, so the conversation can continue
+// from what the user actually saw. Tool calls of the interrupted completion are dropped.
+// When the session waits for tool call approvals or executions, the results which were already sent
+// are added to the chat, the other calls stay unanswered in the history and can be answered later with Chat.Resume.
+// Their results are added after the kept ones, so the tool messages may not follow the order of the calls
+func (h *SessionHandle) Interrupt() {
+	h.interruptOnce.Do(func() { close(h.interrupt) })
+}
+
+// Done returns a channel that is closed when the session ends
+func (h *SessionHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the session ends and returns SessionResult.Err
+func (h *SessionHandle) Wait() error {
+	return h.Result().Err
+}
+
+// Result blocks until the session ends and returns its summary
+func (h *SessionHandle) Result() SessionResult {
+	<-h.done
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.result
+}
@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func TestSessionHandle_ResolveByID(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	c.AppendEvent(NewEventUserMessage("hi"))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "tool", `{}`),
+			NewEventUsage(Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}),
+		},
+		{
+			NewEventToken("done"),
+			NewEventUsage(Usage{PromptTokens: 15, CompletionTokens: 1, TotalTokens: 16}),
+		},
+	})
+
+	h := c.Start(context.Background(), mockClient)
+
+	// approvals arrive independently of the event stream
+	approvals := make(chan string, 1)
+	go func() {
+		for callID := range approvals {
+			assert.NoError(t, h.Resolve(callID, Verdict{Accepted: true}))
+		}
+	}()
+	defer close(approvals)
+
+	for event := range h.Events() {
+		if tc, ok := event.(EventToolCall); ok {
+			approvals <- tc.CallID
+		}
+	}
+
+	result := h.Result()
+	require.NoError(t, result.Err)
+	assert.Equal(t, 2, result.Rounds)
+	assert.Equal(t, Usage{PromptTokens: 25, CompletionTokens: 3, TotalTokens: 28}, result.Usage)
+
+	require.Len(t, result.Messages, 3)
+	assert.Equal(t, "call-1", result.Messages[0].(EventToolCall).CallID)
+	assert.Equal(t, NewEventToolMessage("call-1", "result", true), stripMetadata(result.Messages[1]))
+	assert.Equal(t, NewEventAssistantMessage("done"), stripMetadata(result.Messages[2]))
+}
+
+func TestSessionHandle_ResolveUnknownCall(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	h := c.Start(context.Background(), NewMultiRoundMockClient(nil))
+
+	assert.ErrorIs(t, h.Resolve("missing", Verdict{Accepted: true}), ErrToolCallNotFound)
+	for range h.Events() {
+	}
+	assert.NoError(t, h.Wait())
+}
+
+func TestSessionHandle_ResolveValidatesArguments(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{},
+	})
+
+	h := c.Start(context.Background(), mockClient)
+	for event := range h.Events() {
+		if _, ok := event.(EventCompletionEnded); ok {
+			if _, pending := c.ToolCall("call-1"); !pending {
+				continue
+			}
+			assert.ErrorIs(t, h.Resolve("call-1", Verdict{Accepted: true, Arguments: `{"a":`}), tools.ErrInvalidArguments)
+			assert.NoError(t, h.Resolve("call-1", Verdict{Accepted: false, Reason: "no"}))
+		}
+	}
+
+	assert.Contains(t, withoutMetadata(c.Messages.Snapshot()), NewEventToolMessage("call-1", "no", false))
+}
+
+func TestSessionHandle_Cancel(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+	})
+
+	h := c.Start(context.Background(), mockClient)
+
+	// the consumer stops reading the events once the call arrives
+	for event := range h.Events() {
+		if _, ok := event.(EventToolCall); ok {
+			break
+		}
+	}
+	h.Cancel()
+
+	select {
+	case <-h.Done():
+	case <-time.After(time.Second):
+		t.Fatal("session did not end after Cancel")
+	}
+	assert.ErrorIs(t, h.Wait(), context.Canceled)
+}
+
+func TestSessionHandle_ErrorResult(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMockClient()
+	mockClient.SetStreamingEvents([]StreamEvent{NewEventToken("partial")})
+	streamErr := errors.New("stream failed")
+	mockClient.SetStreamingError(streamErr)
+
+	h := c.Start(context.Background(), mockClient)
+	for range h.Events() {
+	}
+
+	result := h.Result()
+	assert.ErrorIs(t, result.Err, streamErr)
+	assert.Equal(t, 1, result.Rounds)
+}
@@ -0,0 +1,81 @@
+package chat
+
+import "context"
+
+// Handler receives the events of a session started with Chat.Run. Every callback is optional
+type Handler struct {
+	// OnToken receives the text streamed by the assistant
+	OnToken func(text string)
+	// OnThinking receives the reasoning streamed by the assistant
+	OnThinking func(text string)
+	// OnToolCallToken receives the arguments of a tool call while they're streamed
+	OnToolCallToken func(event EventToolCallToken)
+	// OnToolCall decides on the tool calls the approval policy deferred.
+	// Without the callback such calls are declined
+	OnToolCall func(call EventToolCall) Verdict
+	// OnToolResult receives the results of the tool calls, including the declined ones
+	OnToolResult func(result EventToolMessage)
+	// OnCompletionEnd is called when the completion ends, before the tool calls are executed
+	OnCompletionEnd func(event EventCompletionEnded)
+	// OnError receives the errors of the session, including verdicts of OnToolCall which could not be applied
+	OnError func(err error)
+}
+
+// Run runs a session, passing its events to the handler, and blocks until the session ends.
+// The error is the one of SessionResult
+func (c *Chat) Run(ctx context.Context, client Client, handler Handler) error {
+	h := c.Start(ctx, client)
+
+	for event := range h.Events() {
+		switch e := event.(type) {
+		case EventToken:
+			if handler.OnToken != nil {
+				handler.OnToken(e.Content)
+			}
+		case EventThinking:
+			if handler.OnThinking != nil {
+				handler.OnThinking(e.Content)
+			}
+		case EventToolCallToken:
+			if handler.OnToolCallToken != nil {
+				handler.OnToolCallToken(e)
+			}
+		case EventToolCall:
+			if !e.Resolved() {
+				handler.resolve(e)
+			}
+		case EventToolMessage:
+			if handler.OnToolResult != nil {
+				handler.OnToolResult(e)
+			}
+		case EventCompletionEnded:
+			if handler.OnCompletionEnd != nil {
+				handler.OnCompletionEnd(e)
+			}
+		case EventError:
+			if handler.OnError != nil {
+				handler.OnError(e.Error)
+			}
+		}
+	}
+
+	return h.Wait()
+}
+
+// resolve resolves the call with the verdict of OnToolCall.
+// A verdict which can't be applied (e.g. invalid edited arguments) is reported and the call is declined with the error
+func (handler Handler) resolve(call EventToolCall) {
+	if handler.OnToolCall == nil {
+		call.Resolve(false)
+		return
+	}
+
+	err := call.ResolveVerdict(handler.OnToolCall(call))
+	if err == nil {
+		return
+	}
+	if handler.OnError != nil {
+		handler.OnError(err)
+	}
+	call.Decline(err.Error())
+}
@@ -0,0 +1,144 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChat_Run(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventThinking("hmm"),
+			NewEventToolCall("call-1", "tool", `{}`),
+		},
+		{NewEventToken("done")},
+	})
+
+	var tokens, thinking []string
+	var calls, argumentTokens []string
+	var results []EventToolMessage
+	var ended int
+
+	err := c.Run(context.Background(), mockClient, Handler{
+		OnToken:    func(text string) { tokens = append(tokens, text) },
+		OnThinking: func(text string) { thinking = append(thinking, text) },
+		OnToolCallToken: func(event EventToolCallToken) {
+			argumentTokens = append(argumentTokens, event.Content)
+		},
+		OnToolCall: func(call EventToolCall) Verdict {
+			calls = append(calls, call.CallID)
+			return Verdict{Accepted: true}
+		},
+		OnToolResult:    func(result EventToolMessage) { results = append(results, result) },
+		OnCompletionEnd: func(event EventCompletionEnded) { ended++ },
+		OnError:         func(err error) { t.Errorf("unexpected error: %v", err) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"done"}, tokens)
+	assert.Equal(t, []string{"hmm"}, thinking)
+	assert.Equal(t, []string{`{}`}, argumentTokens)
+	assert.Equal(t, []string{"call-1"}, calls)
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", "result", true)}, withoutMetadata(results))
+	assert.Equal(t, 2, ended)
+}
+
+func TestChat_RunWithoutOnToolCall(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{NewEventToken("done")},
+	})
+
+	var results []EventToolMessage
+	err := c.Run(context.Background(), mockClient, Handler{
+		OnToolResult: func(result EventToolMessage) { results = append(results, result) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-1", DefaultDeclinedToolMessage, false)}, withoutMetadata(results))
+}
+
+func TestChat_RunInvalidVerdict(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{NewEventToken("done")},
+	})
+
+	var errs []error
+	var results []EventToolMessage
+	err := c.Run(context.Background(), mockClient, Handler{
+		OnToolCall: func(call EventToolCall) Verdict {
+			return Verdict{Accepted: true, Arguments: "not json"}
+		},
+		OnToolResult: func(result EventToolMessage) { results = append(results, result) },
+		OnError:      func(err error) { errs = append(errs, err) },
+	})
+	require.NoError(t, err)
+
+	require.Len(t, errs, 1)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success, "the call is declined")
+	assert.Equal(t, errs[0].Error(), results[0].Content)
+}
+
+func TestChat_RunError(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	streamErr := errors.New("stream failed")
+	mockClient := NewMockClient()
+	mockClient.SetStreamingEvents([]StreamEvent{})
+	mockClient.SetStreamingError(streamErr)
+
+	var errs []error
+	err := c.Run(context.Background(), mockClient, Handler{
+		OnError: func(err error) { errs = append(errs, err) },
+	})
+
+	assert.ErrorIs(t, err, streamErr)
+	assert.Equal(t, []error{streamErr}, errs)
+}
+
+func TestChat_RunSlowOnToolCall(t *testing.T) {
+	c, execCount := newToolChat(t, "tool", "result")
+
+	// the first call is resolved while the arguments of the second one are still streamed
+	round := []StreamEvent{
+		NewEventToolCall("call-1", "tool", `{}`),
+		NewEventToolCall("call-2", "tool", `{"text": "`),
+	}
+	for range 100 {
+		round = append(round, NewEventToolCall("", "", "a"))
+	}
+	round = append(round, NewEventToolCall("", "", `"}`))
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{round, {NewEventToken("done")}})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run(context.Background(), mockClient, Handler{
+			OnToolCall: func(call EventToolCall) Verdict {
+				time.Sleep(50 * time.Millisecond)
+				return Verdict{Accepted: true}
+			},
+		})
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run hangs when OnToolCall is slow")
+	}
+
+	assert.Equal(t, int32(2), execCount.Load())
+	call, ok := withoutMetadata(c.Messages.Snapshot())[1].(EventToolCall)
+	require.True(t, ok)
+	assert.Equal(t, `{"text": "`+strings.Repeat("a", 100)+`"}`, call.Content)
+}
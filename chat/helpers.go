@@ -28,22 +28,28 @@ func (c *Chat) AddMessage(sender Sender, content string) error {
 // EventAssistantMessage, EventToolMessage), not intermediate streaming
 // events like EventToken. Providers are not required to sync such events
 // in their SyncInput implementation.
+// Message events without an ID get an ID and the creation time (see Metadata).
 func (c *Chat) AppendEvent(event StreamEvent) {
-	c.Messages.AddEvent(event)
+	c.Messages.AddEvent(stampMetadata(event))
 }
 
 func (c *Chat) SendStream(ctx context.Context, client Client, sender Sender, content string) <-chan StreamEvent {
 	err := c.AddMessage(sender, content)
 	if err != nil {
-		result := make(chan StreamEvent, 1)
-		result <- NewEventError(err)
-		close(result)
-		return result
+		return errorStream(err)
 	}
 
 	return c.Session(ctx, client)
 }
 
+// errorStream returns a closed stream holding only the error
+func errorStream(err error) <-chan StreamEvent {
+	result := make(chan StreamEvent, 1)
+	result <- NewEventError(err)
+	close(result)
+	return result
+}
+
 func (c *Chat) SendUserStream(ctx context.Context, client Client, content string) <-chan StreamEvent {
 	return c.SendStream(ctx, client, SenderUser{}, content)
 }
@@ -0,0 +1,126 @@
+package chat
+
+import "context"
+
+// CompletionRequest describes the completion a session is about to start
+type CompletionRequest struct {
+	// Round is the number of the completion in the session, starting at 1
+	Round int
+	// Messages are synced to the client once the hooks ran.
+	// Changing them affects only this completion, Chat.Messages stays untouched
+	Messages []StreamEvent
+	// Client is synced with Messages once the hooks ran, hooks may replace it (e.g. to switch the model)
+	Client Client
+}
+
+// Hooks intercept a session at well-defined points, every hook is optional.
+// Hooks of Chat.Hooks run in order, so logging, redaction, metrics and guardrails can be composed
+type Hooks struct {
+	// BeforeCompletion runs before each completion, an error ends the session with EventError
+	BeforeCompletion func(ctx context.Context, req *CompletionRequest) error
+	// OnEvent runs on every event of the provider stream before the session processes it.
+	// The returned event replaces the original one, returning nil drops it.
+	// Dropping the first event of a tool call drops its argument fragments too
+	OnEvent func(ctx context.Context, event StreamEvent) StreamEvent
+	// BeforeToolCall runs before an accepted tool call is executed and may replace it
+	// (the history keeps the arguments approved for the call).
+	// An error skips the execution, the error text is sent to the model as a failed tool message
+	BeforeToolCall func(ctx context.Context, call EventToolCall) (EventToolCall, error)
+	// AfterToolCall runs after a tool call was executed, the returned message replaces the result
+	AfterToolCall func(ctx context.Context, call EventToolCall, result EventToolMessage) EventToolMessage
+	// OnCompletionEnd runs when a completion ends, before its tool calls are awaited
+	OnCompletionEnd func(ctx context.Context, event EventCompletionEnded)
+	// OnError runs on every error of the session
+	OnError func(ctx context.Context, err error)
+}
+
+// Use appends hooks to the chain of the chat
+func (c *Chat) Use(hooks ...Hooks) {
+	c.Hooks = append(c.Hooks, hooks...)
+}
+
+func (c *Chat) beforeCompletion(ctx context.Context, req *CompletionRequest) error {
+	for _, h := range c.Hooks {
+		if h.BeforeCompletion == nil {
+			continue
+		}
+		if err := h.BeforeCompletion(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onEvent returns the event rewritten by the hooks, nil if it was dropped
+func (c *Chat) onEvent(ctx context.Context, event StreamEvent) StreamEvent {
+	for _, h := range c.Hooks {
+		if h.OnEvent == nil {
+			continue
+		}
+		if event = h.OnEvent(ctx, event); event == nil {
+			return nil
+		}
+	}
+	return event
+}
+
+func (c *Chat) beforeToolCall(ctx context.Context, call EventToolCall) (EventToolCall, error) {
+	for _, h := range c.Hooks {
+		if h.BeforeToolCall == nil {
+			continue
+		}
+		var err error
+		if call, err = h.BeforeToolCall(ctx, call); err != nil {
+			return call, err
+		}
+	}
+	return call, nil
+}
+
+func (c *Chat) afterToolCall(ctx context.Context, call EventToolCall, result EventToolMessage) EventToolMessage {
+	for _, h := range c.Hooks {
+		if h.AfterToolCall != nil {
+			result = h.AfterToolCall(ctx, call, result)
+		}
+	}
+	return result
+}
+
+func (c *Chat) onCompletionEnd(ctx context.Context, event EventCompletionEnded) {
+	for _, h := range c.Hooks {
+		if h.OnCompletionEnd != nil {
+			h.OnCompletionEnd(ctx, event)
+		}
+	}
+}
+
+func (c *Chat) onError(ctx context.Context, err error) {
+	for _, h := range c.Hooks {
+		if h.OnError != nil {
+			h.OnError(ctx, err)
+		}
+	}
+}
+
+// syncInput runs the BeforeCompletion hooks and returns the client synced with the chat
+func (c *Chat) syncInput(ctx context.Context, client Client, chat *Chat, round int) (Client, error) {
+	if len(c.Hooks) == 0 {
+		return client.SyncInput(chat), nil
+	}
+
+	req := &CompletionRequest{
+		Round:    round,
+		Messages: chat.Messages.Snapshot(),
+		Client:   client,
+	}
+	if err := c.beforeCompletion(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// the messages are synced from a copy, so the hooks never touch the history
+	messages := NewMessages()
+	messages.Events = req.Messages
+	synced := *chat
+	synced.Messages = messages
+	return req.Client.SyncInput(&synced), nil
+}
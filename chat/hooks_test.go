@@ -0,0 +1,193 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func TestHooks_BeforeCompletion(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	c.AppendEvent(NewEventUserMessage("my password is hunter2"))
+
+	var rounds []int
+	c.Use(Hooks{
+		BeforeCompletion: func(ctx context.Context, req *CompletionRequest) error {
+			rounds = append(rounds, req.Round)
+			for i, msg := range req.Messages {
+				if user, ok := msg.(EventUserMessage); ok {
+					req.Messages[i] = NewEventUserMessage(strings.ReplaceAll(user.Content, "hunter2", "***"))
+				}
+			}
+			return nil
+		},
+	})
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{NewEventToken("ok")}})
+	for range c.Session(context.Background(), mockClient) {
+	}
+
+	assert.Equal(t, []int{1}, rounds)
+	require.NotNil(t, mockClient.SyncedChat)
+	assert.Equal(t, NewEventUserMessage("my password is ***"), mockClient.SyncedChat.Messages.Snapshot()[0])
+	assert.Equal(t, NewEventUserMessage("my password is hunter2"), withoutMetadata(c.Messages.Snapshot())[0], "history stays untouched")
+}
+
+func TestHooks_BeforeCompletionError(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	blocked := errors.New("budget exceeded")
+
+	var hookErrors []error
+	c.Use(Hooks{
+		BeforeCompletion: func(ctx context.Context, req *CompletionRequest) error { return blocked },
+		OnError:          func(ctx context.Context, err error) { hookErrors = append(hookErrors, err) },
+	})
+
+	var received []StreamEvent
+	for event := range c.Session(context.Background(), NewMultiRoundMockClient(nil)) {
+		received = append(received, event)
+	}
+
+	assert.Equal(t, []StreamEvent{NewEventCompletionStart(), NewEventError(blocked)}, received)
+	assert.Equal(t, []error{blocked}, hookErrors)
+}
+
+func TestHooks_OnEventRewritesAndDrops(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	c.Use(
+		Hooks{
+			OnEvent: func(ctx context.Context, event StreamEvent) StreamEvent {
+				if _, ok := event.(EventThinking); ok {
+					return nil
+				}
+				return event
+			},
+		},
+		Hooks{
+			OnEvent: func(ctx context.Context, event StreamEvent) StreamEvent {
+				if token, ok := event.(EventToken); ok {
+					return NewEventToken(strings.ToUpper(token.Content))
+				}
+				return event
+			},
+		},
+	)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventThinking("hmm"), NewEventToken("hello")},
+	})
+
+	var received []StreamEvent
+	for event := range c.Session(context.Background(), mockClient) {
+		received = append(received, event)
+	}
+
+	assert.Contains(t, received, NewEventToken("HELLO"))
+	assert.NotContains(t, received, NewEventThinking("hmm"))
+	assert.Equal(t, []StreamEvent{NewEventAssistantMessage("HELLO")}, withoutMetadata(c.Messages.Snapshot()))
+}
+
+func TestHooks_OnEventDropsToolCall(t *testing.T) {
+	c, execCount := newToolChat(t, "tool", "result", tools.WithAutoApprove())
+	c.Use(Hooks{
+		OnEvent: func(ctx context.Context, event StreamEvent) StreamEvent {
+			if call, ok := event.(EventToolCall); ok && call.CallID == "call-1" {
+				return nil
+			}
+			return event
+		},
+	})
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "tool", `{"a": `),
+			NewEventToolCall("", "", `"dropped"}`),
+			NewEventToolCall("call-2", "tool", `{"a": `),
+			NewEventToolCall("", "", `"kept"}`),
+		},
+		{},
+	})
+
+	var calls []string
+	for event := range c.Session(context.Background(), mockClient) {
+		if token, ok := event.(EventToolCallToken); ok {
+			calls = append(calls, token.CallID)
+		}
+	}
+
+	// the fragments of the dropped call neither panic nor leak into the next call
+	assert.Equal(t, []string{"call-2", "call-2"}, calls)
+	assert.Equal(t, int32(1), execCount.Load())
+	messages := c.Messages.Snapshot()
+	require.NotEmpty(t, messages)
+	call, ok := messages[0].(EventToolCall)
+	require.True(t, ok)
+	assert.Equal(t, "call-2", call.CallID)
+	assert.Equal(t, `{"a": "kept"}`, call.Content)
+}
+
+func TestHooks_ToolExecution(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools(), ApprovalPolicy: AllowList("tool")}
+	tool, err := tools.NewTool("tool", "", func(input struct {
+		Path string `json:"path"`
+	}) (string, error) {
+		return "secret " + input.Path, nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(tool))
+
+	var completions int
+	c.Use(
+		Hooks{
+			BeforeToolCall: func(ctx context.Context, call EventToolCall) (EventToolCall, error) {
+				if strings.Contains(call.Content, "/etc") {
+					return call, errors.New("access denied")
+				}
+				return call, nil
+			},
+			AfterToolCall: func(ctx context.Context, call EventToolCall, result EventToolMessage) EventToolMessage {
+				result.Content = strings.ReplaceAll(result.Content, "secret", "[redacted]")
+				return result
+			},
+			OnCompletionEnd: func(ctx context.Context, event EventCompletionEnded) {
+				completions++
+			},
+		},
+	)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "tool", `{"path":"a.txt"}`),
+			NewEventToolCall("call-2", "tool", `{"path":"/etc/passwd"}`),
+		},
+		{},
+	})
+	for range c.Session(context.Background(), mockClient) {
+	}
+
+	messages := withoutMetadata(c.Messages.Snapshot())
+	assert.Contains(t, messages, NewEventToolMessage("call-1", "[redacted] a.txt", true))
+	assert.Contains(t, messages, NewEventToolMessage("call-2", "access denied", false))
+	assert.Equal(t, 2, completions)
+}
+
+func TestHooks_OnError(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	streamErr := errors.New("stream failed")
+
+	var hookErrors []error
+	c.Use(Hooks{OnError: func(ctx context.Context, err error) { hookErrors = append(hookErrors, err) }})
+
+	mockClient := NewMockClient()
+	mockClient.SetStreamingEvents([]StreamEvent{NewEventToken("partial")})
+	mockClient.SetStreamingError(streamErr)
+	for range c.Session(context.Background(), mockClient) {
+	}
+
+	assert.Equal(t, []error{streamErr}, hookErrors)
+}
@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"context"
+	"iter"
+)
+
+// CompleteSeq is Complete as an iterator, see SessionSeq
+func (c *Chat) CompleteSeq(ctx context.Context, client Client) iter.Seq2[StreamEvent, error] {
+	return seq(ctx, func(ctx context.Context) <-chan StreamEvent {
+		return c.Complete(ctx, client)
+	})
+}
+
+// SessionSeq is Session as an iterator. Every event is yielded with a nil error,
+// except EventError which is yielded together with its error.
+// Breaking out of the loop cancels the session: the stream is closed, pending tool calls
+// are no longer awaited and the loop returns once the session has ended.
+// Each iteration starts a new session
+func (c *Chat) SessionSeq(ctx context.Context, client Client) iter.Seq2[StreamEvent, error] {
+	return seq(ctx, func(ctx context.Context) <-chan StreamEvent {
+		return c.Session(ctx, client)
+	})
+}
+
+// SendStreamSeq is SendStream as an iterator, see SessionSeq.
+// The message is added when the iteration starts
+func (c *Chat) SendStreamSeq(ctx context.Context, client Client, sender Sender, content string) iter.Seq2[StreamEvent, error] {
+	return seq(ctx, func(ctx context.Context) <-chan StreamEvent {
+		return c.SendStream(ctx, client, sender, content)
+	})
+}
+
+func (c *Chat) SendUserStreamSeq(ctx context.Context, client Client, content string) iter.Seq2[StreamEvent, error] {
+	return c.SendStreamSeq(ctx, client, SenderUser{}, content)
+}
+
+func (c *Chat) SendAssistantStreamSeq(ctx context.Context, client Client, content string) iter.Seq2[StreamEvent, error] {
+	return c.SendStreamSeq(ctx, client, SenderAssistant{}, content)
+}
+
+func (c *Chat) SendSystemStreamSeq(ctx context.Context, client Client, content string) iter.Seq2[StreamEvent, error] {
+	return c.SendStreamSeq(ctx, client, SenderSystem{}, content)
+}
+
+// seq yields the events of the channel returned by run.
+// When the consumer stops early, the context of run is cancelled and the rest of the events is drained,
+// so the producer is not left blocked on the channel
+func seq(ctx context.Context, run func(ctx context.Context) <-chan StreamEvent) iter.Seq2[StreamEvent, error] {
+	return func(yield func(StreamEvent, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		events := run(ctx)
+		defer func() {
+			cancel()
+			for range events {
+			}
+		}()
+
+		for event := range events {
+			var err error
+			if e, ok := event.(EventError); ok {
+				err = e.Error
+			}
+			if !yield(event, err) {
+				return
+			}
+		}
+	}
+}
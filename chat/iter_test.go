@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChat_SessionSeq(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{NewEventToken("done")},
+	})
+
+	var received []StreamEvent
+	for event, err := range c.SessionSeq(context.Background(), mockClient) {
+		require.NoError(t, err)
+		received = append(received, event)
+		if tc, ok := event.(EventToolCall); ok {
+			require.NoError(t, tc.Resolve(true))
+		}
+	}
+
+	assert.Contains(t, received, StreamEvent(NewEventToken("done")))
+	assert.Equal(t, NewEventAssistantMessage("done"), withoutMetadata(c.Messages.Snapshot())[2])
+}
+
+func TestChat_SessionSeqBreak(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{NewEventToken("unreachable")},
+	})
+
+	for event := range c.SessionSeq(context.Background(), mockClient) {
+		if _, ok := event.(EventToolCall); ok {
+			break
+		}
+	}
+
+	// the session has ended by the time the loop returns
+	_, ok := c.ToolCall("call-1")
+	assert.False(t, ok, "pending calls are no longer awaited")
+	messages := c.Messages.Snapshot()
+	require.Len(t, messages, 1, "the call stays unanswered in the history")
+	assert.Equal(t, "call-1", messages[0].(EventToolCall).CallID)
+}
+
+func TestChat_SendUserStreamSeqError(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	streamErr := errors.New("stream failed")
+	mockClient := NewMockClient()
+	mockClient.SetStreamingEvents([]StreamEvent{})
+	mockClient.SetStreamingError(streamErr)
+
+	seq := c.SendUserStreamSeq(context.Background(), mockClient, "hi")
+	assert.Empty(t, c.Messages.Snapshot(), "the message is added when the iteration starts")
+
+	var errs []error
+	for event, err := range seq {
+		if err != nil {
+			assert.IsType(t, EventError{}, event)
+			errs = append(errs, err)
+		}
+	}
+
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], streamErr)
+	assert.Equal(t, NewEventUserMessage("hi"), withoutMetadata(c.Messages.Snapshot())[0])
+}
+
+func TestChat_CompleteSeq(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToken("a"), NewEventToken("b")},
+	})
+
+	var received []StreamEvent
+	for event, err := range c.CompleteSeq(context.Background(), mockClient) {
+		require.NoError(t, err)
+		received = append(received, event)
+		break
+	}
+
+	assert.Equal(t, []StreamEvent{NewEventToken("a")}, received)
+}
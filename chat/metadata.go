@@ -0,0 +1,127 @@
+package chat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Metadata describes a message stored in the chat. It's never sent to the model (see Client.SyncInput)
+type Metadata struct {
+	// ID identifies the message, it's assigned when the message is added to the chat (see Messages.IndexOf)
+	ID string `json:"id,omitempty"`
+	// CreatedAt is the time the message was added to the chat
+	CreatedAt time.Time `json:"created_at,omitzero"`
+
+	// details of the completion which generated the message, reported by the connector with EventGeneration
+
+	Model        string `json:"model,omitempty"`
+	GenerationID string `json:"generation_id,omitempty"`
+
+	// Latency is the time it took to produce the message: the completion for the output of the model,
+	// the execution for tool results
+	Latency time.Duration `json:"latency,omitempty"`
+	// Usage is the token usage of the completion which generated the message.
+	// Messages generated by the same completion report the same usage
+	Usage Usage `json:"usage,omitzero"`
+}
+
+// MessageEvent is implemented by the events stored in Messages
+type MessageEvent interface {
+	StreamEvent
+	Metadata() Metadata
+	withMetadata(meta Metadata) MessageEvent
+}
+
+func (e EventUserMessage) Metadata() Metadata      { return e.Meta }
+func (e EventAssistantMessage) Metadata() Metadata { return e.Meta }
+func (e EventReasoningMessage) Metadata() Metadata { return e.Meta }
+func (e EventSystemMessage) Metadata() Metadata    { return e.Meta }
+func (e EventToolMessage) Metadata() Metadata      { return e.Meta }
+func (e EventToolCall) Metadata() Metadata         { return e.Meta }
+func (e EventRefusal) Metadata() Metadata          { return e.Meta }
+
+func (e EventUserMessage) withMetadata(meta Metadata) MessageEvent {
+	e.Meta = meta
+	return e
+}
+
+func (e EventAssistantMessage) withMetadata(meta Metadata) MessageEvent {
+	e.Meta = meta
+	return e
+}
+
+func (e EventReasoningMessage) withMetadata(meta Metadata) MessageEvent {
+	e.Meta = meta
+	return e
+}
+
+func (e EventSystemMessage) withMetadata(meta Metadata) MessageEvent {
+	e.Meta = meta
+	return e
+}
+
+func (e EventToolMessage) withMetadata(meta Metadata) MessageEvent {
+	e.Meta = meta
+	return e
+}
+
+func (e EventToolCall) withMetadata(meta Metadata) MessageEvent {
+	e.Meta = meta
+	return e
+}
+
+func (e EventRefusal) withMetadata(meta Metadata) MessageEvent {
+	e.Meta = meta
+	return e
+}
+
+// NewMessageID returns a random message ID
+func NewMessageID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "msg_" + hex.EncodeToString(b)
+}
+
+// stampMetadata assigns an ID and the creation time to a message event which doesn't have an ID yet.
+// Other events are returned as they are
+func stampMetadata(event StreamEvent) StreamEvent {
+	message, ok := event.(MessageEvent)
+	if !ok {
+		return event
+	}
+	return stamp(message)
+}
+
+func stamp[T MessageEvent](event T) T {
+	meta := event.Metadata()
+	if meta.ID != "" {
+		return event
+	}
+	meta.ID = NewMessageID()
+	meta.CreatedAt = time.Now().Round(0)
+	return event.withMetadata(meta).(T)
+}
+
+// withGeneration returns the event with the completion details of the generation
+func withGeneration[T MessageEvent](event T, generation Metadata) T {
+	meta := event.Metadata()
+	meta.Model = generation.Model
+	meta.GenerationID = generation.GenerationID
+	meta.Latency = generation.Latency
+	meta.Usage = generation.Usage
+	return event.withMetadata(meta).(T)
+}
+
+// IndexOf returns the position of the message with the ID, or -1 if there's no such message
+func (m *Messages) IndexOf(id string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, event := range m.Events {
+		if message, ok := event.(MessageEvent); ok && message.Metadata().ID == id {
+			return i
+		}
+	}
+	return -1
+}
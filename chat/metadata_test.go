@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Metadata(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	require.NoError(t, c.AddMessage(SenderUser{}, "hi"))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventGeneration("model-a", "gen-1"),
+			NewEventThinking("hmm"),
+			NewEventToken("calling"),
+			NewEventToolCall("call-1", "tool", `{}`),
+			NewEventUsage(Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}),
+		},
+		{NewEventGeneration("model-a", "gen-2"), NewEventToken("done")},
+	})
+
+	streamed := make(map[string]string) // call ID -> message ID
+	for event := range c.Session(context.Background(), mockClient) {
+		switch e := event.(type) {
+		case EventToolCall:
+			streamed[e.CallID] = e.Meta.ID
+			require.NoError(t, e.Resolve(true))
+		case EventToolMessage:
+			streamed["result:"+e.CallID] = e.Meta.ID
+		}
+	}
+
+	messages := c.Messages.Snapshot()
+	require.Len(t, messages, 6)
+
+	ids := make(map[string]bool)
+	for i, event := range messages {
+		meta := event.(MessageEvent).Metadata()
+		assert.NotEmpty(t, meta.ID, "message %d has an ID", i)
+		assert.False(t, meta.CreatedAt.IsZero(), "message %d has a creation time", i)
+		assert.False(t, ids[meta.ID], "IDs are unique")
+		ids[meta.ID] = true
+		assert.Equal(t, i, c.Messages.IndexOf(meta.ID))
+	}
+
+	// the output of the model carries the details of its completion
+	firstRound := Metadata{Model: "model-a", GenerationID: "gen-1", Usage: Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}}
+	for _, event := range messages[1:4] {
+		meta := event.(MessageEvent).Metadata()
+		assert.Equal(t, firstRound.Model, meta.Model)
+		assert.Equal(t, firstRound.GenerationID, meta.GenerationID)
+		assert.Equal(t, firstRound.Usage, meta.Usage)
+		assert.Positive(t, meta.Latency)
+	}
+	assert.Equal(t, "gen-2", messages[5].(EventAssistantMessage).Meta.GenerationID)
+	assert.Empty(t, messages[0].(EventUserMessage).Meta.Model)
+
+	// the consumer sees the IDs of the stored messages
+	assert.Equal(t, messages[3].(EventToolCall).Meta.ID, streamed["call-1"])
+	assert.Equal(t, messages[4].(EventToolMessage).Meta.ID, streamed["result:call-1"])
+
+	// metadata is never sent to the model
+	assert.Equal(t, []StreamEvent{
+		NewEventUserMessage("hi"),
+		NewEventReasoningMessage("hmm"),
+		NewEventAssistantMessage("calling"),
+	}, withoutMetadata(mockClient.SyncedChat.Messages.Snapshot()[:3]))
+}
+
+func TestMessages_IndexOf(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	c.AppendEvent(EventUserMessage{EventBase: EventBase{Content: "hi"}, Meta: Metadata{ID: "msg_custom"}})
+	c.AppendEvent(NewEventSystemMessage("be nice"))
+
+	assert.Equal(t, 0, c.Messages.IndexOf("msg_custom"), "existing IDs are kept")
+	assert.Equal(t, -1, c.Messages.IndexOf("missing"))
+}
+
+func TestMessages_ReplaceUserMessageKeepsID(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	require.NoError(t, c.AddMessage(SenderUser{}, "hi"))
+	id := c.Messages.Snapshot()[0].(EventUserMessage).Meta.ID
+
+	require.NoError(t, c.Messages.ReplaceUserMessage(0, "hello"))
+
+	assert.Equal(t, 0, c.Messages.IndexOf(id))
+}
@@ -0,0 +1,86 @@
+package chat
+
+import "encoding/json"
+
+const DefaultIncompleteToolMessage = "Tool call arguments are not a complete JSON, the call was not executed"
+
+const DefaultFailedCompletionReason = "The completion failed, the call was not executed"
+
+// StreamFailurePolicy is applied to the partial output of a completion whose stream failed
+type StreamFailurePolicy string
+
+const (
+	// FailureCommitMarked adds the partial text and reasoning to the chat marked with PartialError
+	FailureCommitMarked StreamFailurePolicy = "commit_marked"
+	// FailureCommitText adds the partial text and reasoning to the chat like a regular message
+	FailureCommitText StreamFailurePolicy = "commit_text"
+	// FailureDiscard drops the partial output
+	FailureDiscard StreamFailurePolicy = "discard"
+)
+
+// handleFailedCompletion applies Chat.StreamFailurePolicy to the output of the failed completion
+// and ends the session without executing its tool calls
+func (c *Chat) handleFailedCompletion(state *sessionState) (proceed bool) {
+	switch c.StreamFailurePolicy {
+	case FailureDiscard:
+	case FailureCommitText:
+		c.commitPartial(state, "")
+	default:
+		c.commitPartial(state, PartialError)
+	}
+
+	// the calls which were already sent are declined, including the ones the policy accepted.
+	// The last call is cut off by the failure, it was never sent
+	sent := state.toolCalls
+	if state.lastToolCall != nil {
+		sent = sent[:len(sent)-1]
+	}
+	for _, call := range sent {
+		call.claim()
+		if !state.send(EventToolCallResolved{CallID: call.CallID, Reason: DefaultFailedCompletionReason}) {
+			return false
+		}
+	}
+
+	ended := NewEventCompletionEnded(nil)
+	c.onCompletionEnd(state.ctx, ended)
+	state.send(ended)
+	return false
+}
+
+// incompleteArguments reports whether the arguments of the call were cut off, such call must never be executed
+func incompleteArguments(call EventToolCall) bool {
+	return call.Content != "" && !json.Valid([]byte(call.Content))
+}
+
+// commitPartial adds the output collected so far to the chat, marked with the reason it's incomplete.
+// Tool calls of the completion are not committed, they never get a tool message
+func (c *Chat) commitPartial(state *sessionState, reason PartialReason) {
+	generation := state.completion()
+	if state.thinkingBuilder.Len() != 0 {
+		reasoning := withGeneration(NewEventReasoningMessage(state.thinkingBuilder.String()), generation)
+		reasoning.Partial = reason
+		c.AppendEvent(reasoning)
+	}
+	if state.builder.Len() != 0 {
+		message := withGeneration(NewEventAssistantMessage(state.builder.String()), generation)
+		message.Partial = reason
+		c.AppendEvent(message)
+	}
+}
+
+// interrupted reports whether the session was asked to stop (see SessionHandle.Interrupt)
+func (s *sessionState) interrupted() bool {
+	select {
+	case <-s.interrupt:
+		return true
+	default:
+		return false
+	}
+}
+
+// interruptCompletion commits what the consumer has already seen and ends the session
+func (c *Chat) interruptCompletion(state *sessionState) {
+	c.commitPartial(state, PartialInterrupted)
+	state.send(NewEventInterrupted())
+}
@@ -0,0 +1,260 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+// hangingStream emits its events and then blocks until the context is cancelled
+type hangingStream struct {
+	events []StreamEvent
+	index  int
+	err    error
+}
+
+func (s *hangingStream) Next(ctx context.Context) bool {
+	if s.index < len(s.events) {
+		s.index++
+		return true
+	}
+	<-ctx.Done()
+	s.err = ctx.Err()
+	return false
+}
+
+func (s *hangingStream) Current() StreamEvent { return s.events[s.index-1] }
+func (s *hangingStream) Err() error           { return s.err }
+func (s *hangingStream) Close() error         { return nil }
+
+// hangingClient streams the events of its first round and hangs
+type hangingClient struct {
+	events []StreamEvent
+}
+
+func (c *hangingClient) NewStreaming(ctx context.Context) Stream[StreamEvent] {
+	return &hangingStream{events: c.events}
+}
+
+func (c *hangingClient) SyncInput(chat *Chat) Client { return c }
+
+func TestSessionHandle_Interrupt(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	c.AppendEvent(NewEventUserMessage("tell me a story"))
+
+	client := &hangingClient{events: []StreamEvent{
+		NewEventThinking("let me think"),
+		NewEventToken("Once upon"),
+		NewEventToken(" a time"),
+		NewEventToolCall("call-1", "tool", `{"a":`),
+	}}
+
+	h := c.Start(context.Background(), client)
+
+	var received []StreamEvent
+	for event := range h.Events() {
+		received = append(received, event)
+		if token, ok := event.(EventToken); ok && token.Content == " a time" {
+			h.Interrupt()
+		}
+	}
+
+	select {
+	case <-h.Done():
+	case <-time.After(time.Second):
+		t.Fatal("session did not end after Interrupt")
+	}
+
+	result := h.Result()
+	assert.NoError(t, result.Err)
+	assert.True(t, result.Interrupted)
+	assert.Equal(t, NewEventInterrupted(), received[len(received)-1])
+
+	reasoning := NewEventReasoningMessage("let me think")
+	reasoning.Partial = PartialInterrupted
+	message := NewEventAssistantMessage("Once upon a time")
+	message.Partial = PartialInterrupted
+	assert.Equal(t, []StreamEvent{NewEventUserMessage("tell me a story"), reasoning, message}, withoutMetadata(c.Messages.Snapshot()))
+
+	_, ok := c.ToolCall("call-1")
+	assert.False(t, ok, "tool calls of the interrupted completion are dropped")
+}
+
+func TestSessionHandle_InterruptAwaitingApproval(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+	})
+
+	h := c.Start(context.Background(), mockClient)
+	for event := range h.Events() {
+		if _, ok := event.(EventCompletionEnded); ok {
+			h.Interrupt()
+		}
+	}
+
+	assert.True(t, h.Result().Interrupted)
+
+	pending := c.Messages.PendingToolCalls()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "call-1", pending[0].CallID)
+}
+
+func TestSessionHandle_InterruptKeepsFinishedResults(t *testing.T) {
+	c, _ := newToolChat(t, "fast", "fast result", tools.WithAutoApprove())
+	c.ParallelToolCalls = 2
+	// only the first execution of the slow tool outlives the session
+	var slowCount atomic.Int32
+	slow, err := tools.NewToolCtx("slow", "", func(ctx context.Context, input map[string]string) (string, error) {
+		if slowCount.Add(1) > 1 {
+			return "slow result", nil
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(slow, tools.WithAutoApprove()))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{
+		NewEventToolCall("call-1", "slow", `{}`),
+		NewEventToolCall("call-2", "fast", `{}`),
+	}})
+
+	h := c.Start(context.Background(), mockClient)
+	for event := range h.Events() {
+		if message, ok := event.(EventToolMessage); ok && message.CallID == "call-2" {
+			h.Interrupt()
+		}
+	}
+
+	assert.True(t, h.Result().Interrupted)
+
+	// the result of call-2 was already sent, it must not be executed again on resume
+	assert.Equal(t, []EventToolMessage{NewEventToolMessage("call-2", "fast result", true)},
+		toolMessagesOf(c.Messages.Snapshot()))
+	pending := c.Messages.PendingToolCalls()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "call-1", pending[0].CallID)
+
+	for range c.Resume(context.Background(), NewMultiRoundMockClient([][]StreamEvent{{}})) {
+	}
+
+	// the result of call-1 follows the one which was kept, the order of the calls isn't restored
+	assert.Equal(t, []EventToolMessage{
+		NewEventToolMessage("call-2", "fast result", true),
+		NewEventToolMessage("call-1", "slow result", true),
+	}, toolMessagesOf(c.Messages.Snapshot()))
+}
+
+func newFailingClient(events []StreamEvent) *MockClient {
+	mockClient := NewMockClient()
+	mockClient.SetStreamingEvents(events)
+	mockClient.SetStreamingError(errors.New("connection reset"))
+	return mockClient
+}
+
+func TestSession_StreamFailurePolicies(t *testing.T) {
+	events := []StreamEvent{
+		NewEventThinking("hmm"),
+		NewEventToken("partial"),
+		NewEventToolCall("call-1", "tool", `{"a":`),
+	}
+
+	marked := NewEventAssistantMessage("partial")
+	marked.Partial = PartialError
+	markedReasoning := NewEventReasoningMessage("hmm")
+	markedReasoning.Partial = PartialError
+
+	tests := []struct {
+		policy   StreamFailurePolicy
+		expected []StreamEvent
+	}{
+		{policy: "", expected: []StreamEvent{markedReasoning, marked}},
+		{policy: FailureCommitMarked, expected: []StreamEvent{markedReasoning, marked}},
+		{policy: FailureCommitText, expected: []StreamEvent{NewEventReasoningMessage("hmm"), NewEventAssistantMessage("partial")}},
+		{policy: FailureDiscard, expected: []StreamEvent{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			c := &Chat{Messages: NewMessages(), Tools: tools.NewTools(), StreamFailurePolicy: tt.policy}
+			mockClient := newFailingClient(events)
+
+			var received []StreamEvent
+			for event := range c.Session(context.Background(), mockClient) {
+				received = append(received, event)
+				_, isCall := event.(EventToolCall)
+				assert.False(t, isCall, "incomplete tool calls are never surfaced")
+			}
+
+			assert.Equal(t, tt.expected, withoutMetadata(c.Messages.Snapshot()))
+			assert.Equal(t, NewEventCompletionEnded(nil), received[len(received)-1])
+		})
+	}
+}
+
+func TestSession_StreamFailureNeverExecutesToolCalls(t *testing.T) {
+	var executed atomic.Int32
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools(), ApprovalPolicy: AllowList("tool")}
+	tool, err := tools.NewTool("tool", "", func(input map[string]string) (string, error) {
+		executed.Add(1)
+		return "", nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(tool))
+
+	var ended []EventCompletionEnded
+	c.Use(Hooks{OnCompletionEnd: func(ctx context.Context, event EventCompletionEnded) {
+		ended = append(ended, event)
+	}})
+
+	mockClient := newFailingClient([]StreamEvent{
+		NewEventToolCall("call-1", "tool", `{}`),
+		NewEventToolCall("call-2", "tool", `{"input":`),
+	})
+	var resolved []EventToolCallResolved
+	for event := range c.Session(context.Background(), mockClient) {
+		if e, ok := event.(EventToolCallResolved); ok {
+			resolved = append(resolved, e)
+		}
+	}
+
+	assert.Equal(t, int32(0), executed.Load())
+	assert.Empty(t, c.Messages.Snapshot())
+	assert.Equal(t, 1, mockClient.CallCount, "the session ends after the failed completion")
+
+	// the call accepted by the policy is reported as declined, the cut off one was never sent
+	assert.Equal(t, []EventToolCallResolved{{CallID: "call-1", Reason: DefaultFailedCompletionReason}}, resolved)
+	assert.Equal(t, []EventCompletionEnded{NewEventCompletionEnded(nil)}, ended)
+}
+
+func TestSession_IncompleteArgumentsAreDeclined(t *testing.T) {
+	var executed atomic.Int32
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	tool, err := tools.NewTool("tool", "", func(input map[string]string) (string, error) {
+		executed.Add(1)
+		return "", nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(tool, tools.WithAutoApprove()))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{"input": {"a"`)},
+		{},
+	})
+
+	for event := range c.Session(context.Background(), mockClient) {
+		if tc, ok := event.(EventToolCall); ok {
+			assert.True(t, tc.Resolved())
+		}
+	}
+
+	assert.Equal(t, int32(0), executed.Load())
+	assert.Contains(t, withoutMetadata(c.Messages.Snapshot()), NewEventToolMessage("call-1", DefaultIncompleteToolMessage, false))
+}
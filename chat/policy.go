@@ -0,0 +1,163 @@
+package chat
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// ApprovalDecision is the decision of an ApprovalPolicy about a single tool call
+type ApprovalDecision uint
+
+const (
+	// ApprovalDefer leaves the tool call to the consumer (EventToolCall.Resolve)
+	ApprovalDefer ApprovalDecision = iota
+	// ApprovalApprove executes the tool call without asking the consumer
+	ApprovalApprove
+	// ApprovalDeny declines the tool call without asking the consumer
+	ApprovalDeny
+)
+
+// ApprovalRequest describes a tool call that waits for approval
+type ApprovalRequest struct {
+	Call EventToolCall
+	Name string
+	// Arguments are the parsed arguments of the call, nil if they are not a valid JSON object
+	Arguments map[string]any
+	Chat      *Chat
+}
+
+// ApprovalResult is returned by ApprovalPolicy
+type ApprovalResult struct {
+	Decision ApprovalDecision
+	// Reason is sent back to the model as the tool result when the call is denied
+	Reason string
+}
+
+// ApprovalPolicy decides whether a tool call is approved, denied or deferred to the consumer.
+// Deferred calls are resolved through the usual EventToolCall.Resolve flow
+type ApprovalPolicy interface {
+	Decide(req ApprovalRequest) ApprovalResult
+}
+
+// ApprovalPolicyFunc is an adapter to use ordinary functions as approval policies
+type ApprovalPolicyFunc func(req ApprovalRequest) ApprovalResult
+
+func (f ApprovalPolicyFunc) Decide(req ApprovalRequest) ApprovalResult { return f(req) }
+
+func Approve() ApprovalResult { return ApprovalResult{Decision: ApprovalApprove} }
+
+func Deny(reason string) ApprovalResult {
+	return ApprovalResult{Decision: ApprovalDeny, Reason: reason}
+}
+
+func Defer() ApprovalResult { return ApprovalResult{Decision: ApprovalDefer} }
+
+// AllowList approves calls of the listed tools and defers everything else
+func AllowList(names ...string) ApprovalPolicy {
+	return ApprovalPolicyFunc(func(req ApprovalRequest) ApprovalResult {
+		if slices.Contains(names, req.Name) {
+			return Approve()
+		}
+		return Defer()
+	})
+}
+
+// DenyList denies calls of the listed tools with the given reason and defers everything else
+func DenyList(reason string, names ...string) ApprovalPolicy {
+	return ApprovalPolicyFunc(func(req ApprovalRequest) ApprovalResult {
+		if slices.Contains(names, req.Name) {
+			return Deny(reason)
+		}
+		return Defer()
+	})
+}
+
+// ReadOnlyTools approves calls of tools added with tools.WithReadOnly and defers everything else
+func ReadOnlyTools() ApprovalPolicy {
+	return ApprovalPolicyFunc(func(req ApprovalRequest) ApprovalResult {
+		if req.Chat == nil || req.Chat.Tools == nil {
+			return Defer()
+		}
+		tool, ok := req.Chat.Tools.Get(req.Name)
+		if ok && tool.ReadOnly() {
+			return Approve()
+		}
+		return Defer()
+	})
+}
+
+// ApproveIf approves calls matching the predicate and defers everything else
+func ApproveIf(predicate func(req ApprovalRequest) bool) ApprovalPolicy {
+	return ApprovalPolicyFunc(func(req ApprovalRequest) ApprovalResult {
+		if predicate(req) {
+			return Approve()
+		}
+		return Defer()
+	})
+}
+
+// Policies combines several policies: the first decision other than ApprovalDefer wins
+func Policies(policies ...ApprovalPolicy) ApprovalPolicy {
+	return ApprovalPolicyFunc(func(req ApprovalRequest) ApprovalResult {
+		for _, policy := range policies {
+			if result := policy.Decide(req); result.Decision != ApprovalDefer {
+				return result
+			}
+		}
+		return Defer()
+	})
+}
+
+func newApprovalRequest(c *Chat, call EventToolCall) ApprovalRequest {
+	var arguments map[string]any
+	if err := json.Unmarshal([]byte(call.Content), &arguments); err != nil {
+		arguments = nil
+	}
+
+	return ApprovalRequest{
+		Call:      call,
+		Name:      call.Name,
+		Arguments: arguments,
+		Chat:      c,
+	}
+}
+
+// describeToolCall fills the approval details declared on the tool registration
+func (c *Chat) describeToolCall(call *EventToolCall) {
+	tool, ok := c.Tools.Get(call.Name)
+	if !ok {
+		return
+	}
+	call.Risk = tool.Risk()
+	call.Prompt = tool.ApprovalPrompt(call.Content)
+}
+
+// decideApprovalWith returns the verdict of the policy for the call (the session policy, which is
+// Chat.ApprovalPolicy unless it's replaced), it returns false if the call is deferred to the consumer.
+//
+// Tool options take precedence over the policy:
+//   - tools added with tools.WithAutoApprove are always approved
+//   - tools added with tools.WithRequiresApproval may be denied by the policy, but never approved
+func (c *Chat) decideApprovalWith(policy ApprovalPolicy, call EventToolCall) (Verdict, bool) {
+	tool, registered := c.Tools.Get(call.Name)
+	if registered && tool.AutoApprove() {
+		return Verdict{Accepted: true}, true
+	}
+
+	if policy == nil {
+		return Verdict{}, false
+	}
+
+	result := policy.Decide(newApprovalRequest(c, call))
+	switch result.Decision {
+	case ApprovalApprove:
+		if registered && tool.RequiresApproval() {
+			return Verdict{}, false
+		}
+		return Verdict{Accepted: true}, true
+	case ApprovalDeny:
+		return Verdict{Accepted: false, Reason: result.Reason}, true
+	default:
+		return Verdict{}, false
+	}
+}
@@ -0,0 +1,256 @@
+package chat
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func newPolicyRequest(c *Chat, name, arguments string) ApprovalRequest {
+	return newApprovalRequest(c, NewEventToolCall("call-1", name, arguments))
+}
+
+func TestNewApprovalRequest_ParsesArguments(t *testing.T) {
+	req := newPolicyRequest(nil, "tool", `{"path": "a.txt", "n": 2}`)
+
+	assert.Equal(t, "tool", req.Name)
+	assert.Equal(t, "a.txt", req.Arguments["path"])
+	assert.Equal(t, float64(2), req.Arguments["n"])
+}
+
+func TestNewApprovalRequest_InvalidArguments(t *testing.T) {
+	req := newPolicyRequest(nil, "tool", `{"path": `)
+	assert.Nil(t, req.Arguments)
+}
+
+func TestAllowList(t *testing.T) {
+	policy := AllowList("read_file", "list_dir")
+
+	assert.Equal(t, ApprovalApprove, policy.Decide(newPolicyRequest(nil, "read_file", `{}`)).Decision)
+	assert.Equal(t, ApprovalDefer, policy.Decide(newPolicyRequest(nil, "write_file", `{}`)).Decision)
+}
+
+func TestDenyList(t *testing.T) {
+	policy := DenyList("not allowed", "rm")
+
+	result := policy.Decide(newPolicyRequest(nil, "rm", `{}`))
+	assert.Equal(t, ApprovalDeny, result.Decision)
+	assert.Equal(t, "not allowed", result.Reason)
+	assert.Equal(t, ApprovalDefer, policy.Decide(newPolicyRequest(nil, "ls", `{}`)).Decision)
+}
+
+func TestReadOnlyTools(t *testing.T) {
+	c := &Chat{Tools: tools.NewTools()}
+	readTool, err := tools.NewTool("read", "", func(input map[string]string) (string, error) { return "", nil })
+	require.NoError(t, err)
+	writeTool, err := tools.NewTool("write", "", func(input map[string]string) (string, error) { return "", nil })
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(readTool, tools.WithReadOnly()))
+	require.NoError(t, c.Tools.Add(writeTool))
+
+	policy := ReadOnlyTools()
+
+	assert.Equal(t, ApprovalApprove, policy.Decide(newPolicyRequest(c, "read", `{}`)).Decision)
+	assert.Equal(t, ApprovalDefer, policy.Decide(newPolicyRequest(c, "write", `{}`)).Decision)
+	assert.Equal(t, ApprovalDefer, policy.Decide(newPolicyRequest(c, "missing", `{}`)).Decision)
+	assert.Equal(t, ApprovalDefer, policy.Decide(newPolicyRequest(nil, "read", `{}`)).Decision)
+}
+
+func TestApproveIf(t *testing.T) {
+	policy := ApproveIf(func(req ApprovalRequest) bool {
+		return req.Arguments["path"] == "safe.txt"
+	})
+
+	assert.Equal(t, ApprovalApprove, policy.Decide(newPolicyRequest(nil, "write", `{"path": "safe.txt"}`)).Decision)
+	assert.Equal(t, ApprovalDefer, policy.Decide(newPolicyRequest(nil, "write", `{"path": "/etc/passwd"}`)).Decision)
+}
+
+func TestPolicies_FirstDecisionWins(t *testing.T) {
+	policy := Policies(
+		DenyList("denied", "rm"),
+		AllowList("rm", "ls"),
+	)
+
+	assert.Equal(t, ApprovalDeny, policy.Decide(newPolicyRequest(nil, "rm", `{}`)).Decision)
+	assert.Equal(t, ApprovalApprove, policy.Decide(newPolicyRequest(nil, "ls", `{}`)).Decision)
+	assert.Equal(t, ApprovalDefer, policy.Decide(newPolicyRequest(nil, "cat", `{}`)).Decision)
+}
+
+func TestSession_ApprovalPolicy(t *testing.T) {
+	var readCount, writeCount atomic.Int32
+	c := &Chat{
+		Messages:       NewMessages(),
+		Tools:          tools.NewTools(),
+		ApprovalPolicy: Policies(AllowList("read"), DenyList("writes are disabled", "write")),
+	}
+	readTool, err := tools.NewTool("read", "", func(input map[string]string) (string, error) {
+		readCount.Add(1)
+		return "content", nil
+	})
+	require.NoError(t, err)
+	writeTool, err := tools.NewTool("write", "", func(input map[string]string) (string, error) {
+		writeCount.Add(1)
+		return "written", nil
+	})
+	require.NoError(t, err)
+	c.Tools.Add(readTool)
+	c.Tools.Add(writeTool)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "read", `{}`),
+			NewEventToolCall("call-2", "write", `{}`),
+		},
+		{},
+	})
+
+	var resolved []EventToolCallResolved
+	for event := range c.Session(context.Background(), mockClient) {
+		switch e := event.(type) {
+		case EventToolCall:
+			// consumer can't override the policy decision
+			assert.ErrorIs(t, e.Resolve(true), ErrAlreadyResolved)
+		case EventToolCallResolved:
+			resolved = append(resolved, e)
+		}
+	}
+
+	assert.Equal(t, int32(1), readCount.Load())
+	assert.Equal(t, int32(0), writeCount.Load())
+	assert.ElementsMatch(t, []EventToolCallResolved{
+		{CallID: "call-1", Accepted: true},
+		{CallID: "call-2", Accepted: false, Reason: "writes are disabled"},
+	}, resolved)
+
+	var declined EventToolMessage
+	for _, msg := range c.Messages.Snapshot() {
+		if tm, ok := msg.(EventToolMessage); ok && tm.CallID == "call-2" {
+			declined = tm
+		}
+	}
+	assert.Equal(t, "writes are disabled", declined.Content)
+	assert.False(t, declined.Success)
+}
+
+func TestSession_ApprovalPolicy_DeferredCallsUseEventFlow(t *testing.T) {
+	c := &Chat{
+		Messages:       NewMessages(),
+		Tools:          tools.NewTools(),
+		ApprovalPolicy: AllowList("other"),
+	}
+	tool, err := tools.NewTool("test-tool", "", func(input map[string]string) (string, error) {
+		return "result", nil
+	})
+	require.NoError(t, err)
+	c.Tools.Add(tool)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "test-tool", `{}`)},
+		{},
+	})
+
+	for event := range c.Session(context.Background(), mockClient) {
+		if tc, ok := event.(EventToolCall); ok {
+			assert.NoError(t, tc.Resolve(true))
+		}
+	}
+
+	messages := withoutMetadata(c.Messages.Snapshot())
+	assert.Contains(t, messages, NewEventToolMessage("call-1", "result", true))
+}
+
+func TestDecideApproval_ToolOptions(t *testing.T) {
+	c := &Chat{Tools: tools.NewTools(), ApprovalPolicy: AllowList("read", "write", "plain")}
+	options := map[string][]tools.AddOption{
+		"read":  {tools.WithAutoApprove()},
+		"write": {tools.WithRequiresApproval()},
+		"plain": nil,
+		"auto":  {tools.WithAutoApprove()},
+	}
+	for name, opts := range options {
+		tool, err := tools.NewTool(name, "", func(input map[string]string) (string, error) { return "", nil })
+		require.NoError(t, err)
+		require.NoError(t, c.Tools.Add(tool, opts...))
+	}
+
+	_, decided := c.decideApprovalWith(c.ApprovalPolicy, NewEventToolCall("call-1", "write", `{}`))
+	assert.False(t, decided, "tools requiring approval are never approved by the policy")
+
+	verdict, decided := c.decideApprovalWith(c.ApprovalPolicy, NewEventToolCall("call-2", "plain", `{}`))
+	assert.True(t, decided)
+	assert.True(t, verdict.Accepted)
+
+	c.ApprovalPolicy = nil
+	verdict, decided = c.decideApprovalWith(c.ApprovalPolicy, NewEventToolCall("call-3", "auto", `{}`))
+	assert.True(t, decided, "auto-approved tools don't need a policy")
+	assert.True(t, verdict.Accepted)
+
+	_, decided = c.decideApprovalWith(c.ApprovalPolicy, NewEventToolCall("call-4", "plain", `{}`))
+	assert.False(t, decided)
+}
+
+func TestDecideApproval_PolicyCanDenyRequiredApproval(t *testing.T) {
+	c := &Chat{Tools: tools.NewTools(), ApprovalPolicy: DenyList("no", "write")}
+	tool, err := tools.NewTool("write", "", func(input map[string]string) (string, error) { return "", nil })
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(tool, tools.WithRequiresApproval()))
+
+	verdict, decided := c.decideApprovalWith(c.ApprovalPolicy, NewEventToolCall("call-1", "write", `{}`))
+	assert.True(t, decided)
+	assert.False(t, verdict.Accepted)
+	assert.Equal(t, "no", verdict.Reason)
+}
+
+func TestSession_ToolApprovalOptions(t *testing.T) {
+	c := &Chat{
+		Messages: NewMessages(),
+		Tools:    tools.NewTools(),
+	}
+	readTool, err := tools.NewTool("read_file", "", func(input map[string]string) (string, error) {
+		return "content", nil
+	})
+	require.NoError(t, err)
+	writeTool, err := tools.NewTool("write_file", "", func(input map[string]string) (string, error) {
+		return "written", nil
+	})
+	require.NoError(t, err)
+	c.Tools.Add(readTool, tools.WithAutoApprove(), tools.WithRiskLevel(tools.RiskLow))
+	c.Tools.Add(writeTool,
+		tools.WithRequiresApproval(),
+		tools.WithRiskLevel(tools.RiskHigh),
+		tools.WithApprovalPrompt(func(arguments string) string {
+			return "Write " + arguments + "?"
+		}),
+	)
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "read_file", `{"path":"a"}`),
+			NewEventToolCall("call-2", "write_file", `{"path":"b"}`),
+		},
+		{},
+	})
+
+	var surfaced []EventToolCall
+	for event := range c.Session(context.Background(), mockClient) {
+		if tc, ok := event.(EventToolCall); ok {
+			if !tc.Resolved() {
+				surfaced = append(surfaced, tc)
+				tc.Resolve(true)
+			}
+		}
+	}
+
+	require.Len(t, surfaced, 1)
+	assert.Equal(t, "call-2", surfaced[0].CallID)
+	assert.Equal(t, tools.RiskHigh, surfaced[0].Risk)
+	assert.Equal(t, `Write {"path":"b"}?`, surfaced[0].Prompt)
+
+	messages := withoutMetadata(c.Messages.Snapshot())
+	assert.Contains(t, messages, NewEventToolMessage("call-1", "content", true))
+	assert.Contains(t, messages, NewEventToolMessage("call-2", "written", true))
+}
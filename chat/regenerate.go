@@ -0,0 +1,71 @@
+package chat
+
+import "context"
+
+// RemoveLastTurn removes the last assistant turn: the assistant messages, reasoning, refusals, tool calls
+// and tool results following the last user or system message. It returns the removed events
+// or ErrAssistantMessageNotFound if the history doesn't end with an assistant turn
+func (m *Messages) RemoveLastTurn() ([]StreamEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := len(m.Events)
+	for start > 0 && isAssistantTurn(m.Events[start-1]) {
+		start--
+	}
+	if start == len(m.Events) {
+		return nil, ErrAssistantMessageNotFound
+	}
+
+	removed := append([]StreamEvent(nil), m.Events[start:]...)
+	m.Events = m.Events[:start]
+	return removed, nil
+}
+
+// ReplaceUserMessage replaces the content of the user message at the index and removes every message after it.
+// It returns ErrUserMessageNotFound if there's no user message at the index
+func (m *Messages) ReplaceUserMessage(index int, content string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if index < 0 || index >= len(m.Events) {
+		return ErrUserMessageNotFound
+	}
+	message, ok := m.Events[index].(EventUserMessage)
+	if !ok {
+		return ErrUserMessageNotFound
+	}
+
+	// the edited message keeps its ID, so references to it stay valid
+	message.Content = content
+	m.Events[index] = message
+	m.Events = m.Events[:index+1]
+	return nil
+}
+
+// isAssistantTurn reports whether the message belongs to an assistant turn
+func isAssistantTurn(event StreamEvent) bool {
+	switch event.(type) {
+	case EventAssistantMessage, EventReasoningMessage, EventRefusal, EventToolCall, EventToolMessage:
+		return true
+	default:
+		return false
+	}
+}
+
+// Regenerate removes the last assistant turn (see Messages.RemoveLastTurn) and runs a new session
+func (c *Chat) Regenerate(ctx context.Context, client Client) <-chan StreamEvent {
+	if _, err := c.Messages.RemoveLastTurn(); err != nil {
+		return errorStream(err)
+	}
+	return c.Session(ctx, client)
+}
+
+// EditUserMessage replaces the user message at the index, drops the messages after it
+// (see Messages.ReplaceUserMessage) and runs a new session
+func (c *Chat) EditUserMessage(ctx context.Context, client Client, index int, content string) <-chan StreamEvent {
+	if err := c.Messages.ReplaceUserMessage(index, content); err != nil {
+		return errorStream(err)
+	}
+	return c.Session(ctx, client)
+}
@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages_RemoveLastTurn(t *testing.T) {
+	m := NewMessages()
+	m.AddEvent(NewEventUserMessage("first"))
+	m.AddEvent(NewEventAssistantMessage("first answer"))
+	m.AddEvent(NewEventUserMessage("second"))
+	m.AddEvent(NewEventReasoningMessage("thinking"))
+	m.AddEvent(NewEventToolCall("call-1", "tool", `{}`))
+	m.AddEvent(NewEventToolMessage("call-1", "result", true))
+	m.AddEvent(NewEventAssistantMessage("second answer"))
+
+	removed, err := m.RemoveLastTurn()
+	require.NoError(t, err)
+	assert.Len(t, removed, 4)
+	assert.Equal(t, []StreamEvent{
+		NewEventUserMessage("first"),
+		NewEventAssistantMessage("first answer"),
+		NewEventUserMessage("second"),
+	}, m.Snapshot())
+
+	_, err = m.RemoveLastTurn()
+	assert.ErrorIs(t, err, ErrAssistantMessageNotFound)
+}
+
+func TestChat_Regenerate(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	c.AddMessage(SenderUser{}, "joke please")
+	c.AppendEvent(NewEventAssistantMessage("a bad joke"))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{NewEventToken("a good joke")}})
+	for range c.Regenerate(context.Background(), mockClient) {
+	}
+
+	assert.Equal(t, []StreamEvent{
+		NewEventUserMessage("joke please"),
+		NewEventAssistantMessage("a good joke"),
+	}, withoutMetadata(c.Messages.Snapshot()))
+}
+
+func TestChat_RegenerateWithoutAnswer(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	c.AddMessage(SenderUser{}, "joke please")
+
+	var received []StreamEvent
+	for event := range c.Regenerate(context.Background(), NewMultiRoundMockClient(nil)) {
+		received = append(received, event)
+	}
+
+	require.Len(t, received, 1)
+	assert.ErrorIs(t, received[0].(EventError).Error, ErrAssistantMessageNotFound)
+}
+
+func TestChat_EditUserMessage(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	c.AddMessage(SenderSystem{}, "be nice")
+	c.AddMessage(SenderUser{}, "tell me a joke")
+	c.AppendEvent(NewEventAssistantMessage("a joke"))
+	c.AddMessage(SenderUser{}, "another one")
+	c.AppendEvent(NewEventAssistantMessage("another joke"))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{NewEventToken("a poem")}})
+	for range c.EditUserMessage(context.Background(), mockClient, 1, "tell me a poem") {
+	}
+
+	assert.Equal(t, []StreamEvent{
+		NewEventSystemMessage("be nice"),
+		NewEventUserMessage("tell me a poem"),
+		NewEventAssistantMessage("a poem"),
+	}, withoutMetadata(c.Messages.Snapshot()))
+}
+
+func TestMessages_ReplaceUserMessageNotFound(t *testing.T) {
+	m := NewMessages()
+	m.AddEvent(NewEventSystemMessage("be nice"))
+
+	assert.ErrorIs(t, m.ReplaceUserMessage(0, "x"), ErrUserMessageNotFound)
+	assert.ErrorIs(t, m.ReplaceUserMessage(5, "x"), ErrUserMessageNotFound)
+	assert.ErrorIs(t, m.ReplaceUserMessage(-1, "x"), ErrUserMessageNotFound)
+	assert.Len(t, m.Snapshot(), 1)
+}
@@ -0,0 +1,56 @@
+package chat
+
+import "sync"
+
+// callRegistry keeps the tool calls awaited by the running sessions of a chat, so they can be found by ID
+type callRegistry struct {
+	mu    sync.Mutex
+	calls map[string]EventToolCall
+}
+
+func newCallRegistry() *callRegistry {
+	return &callRegistry{calls: make(map[string]EventToolCall)}
+}
+
+func (r *callRegistry) add(call EventToolCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[call.CallID] = call
+}
+
+func (r *callRegistry) remove(calls []EventToolCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, call := range calls {
+		delete(r.calls, call.CallID)
+	}
+}
+
+func (r *callRegistry) get(callID string) (EventToolCall, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	call, ok := r.calls[callID]
+	return call, ok
+}
+
+// ToolCall returns the tool call with the given ID awaited by a running session of the chat.
+// The call stays available until the session moves on to the next completion or ends
+func (c *Chat) ToolCall(callID string) (EventToolCall, bool) {
+	if c.calls == nil {
+		return EventToolCall{}, false
+	}
+	return c.calls.get(callID)
+}
+
+// Bind re-binds a tool call to the running session of the chat that awaits it,
+// e.g. a call restored with UnmarshalEvent or received from a frontend.
+// After a successful Bind the call resolves the approval of the session like the original event.
+// It returns ErrToolCallNotFound if no running session awaits a call with the same ID
+func (c *Chat) Bind(call *EventToolCall) error {
+	live, ok := c.ToolCall(call.CallID)
+	if !ok || live.Name != call.Name {
+		return ErrToolCallNotFound
+	}
+	*call = live
+	return nil
+}
@@ -0,0 +1,76 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func TestChat_BindDeserializedToolCall(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	tool, err := tools.NewTool("tool", "", func(input map[string]string) (string, error) {
+		return "result", nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(tool))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{},
+	})
+
+	var resolved []EventToolCallResolved
+	for event := range c.Session(context.Background(), mockClient) {
+		switch e := event.(type) {
+		case EventToolCall:
+			// the call travels to a frontend and back as JSON
+			data, err := MarshalEvent(e)
+			require.NoError(t, err)
+			restored, err := UnmarshalEvent(data)
+			require.NoError(t, err)
+
+			call := restored.(EventToolCall)
+			require.ErrorIs(t, call.Resolve(true), ErrToolCallDetached)
+			require.NoError(t, c.Bind(&call))
+			assert.NoError(t, call.Resolve(true))
+		case EventToolCallResolved:
+			resolved = append(resolved, e)
+		}
+	}
+
+	assert.Equal(t, []EventToolCallResolved{{CallID: "call-1", Accepted: true}}, resolved)
+	assert.Contains(t, withoutMetadata(c.Messages.Snapshot()), NewEventToolMessage("call-1", "result", true))
+
+	_, ok := c.ToolCall("call-1")
+	assert.False(t, ok, "calls are released when the session ends")
+}
+
+func TestChat_ToolCallByID(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{},
+	})
+
+	for event := range c.Session(context.Background(), mockClient) {
+		if _, ok := event.(EventCompletionEnded); !ok {
+			continue
+		}
+		call, ok := c.ToolCall("call-1")
+		if ok {
+			assert.NoError(t, call.Decline("no"))
+		}
+	}
+
+	assert.Contains(t, withoutMetadata(c.Messages.Snapshot()), NewEventToolMessage("call-1", "no", false))
+}
+
+func TestChat_BindUnknownToolCall(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+
+	call := NewEventToolCall("call-1", "tool", `{}`)
+	assert.ErrorIs(t, c.Bind(&call), ErrToolCallNotFound)
+}
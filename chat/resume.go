@@ -0,0 +1,65 @@
+package chat
+
+import "context"
+
+// PendingToolCalls returns the tool calls at the end of the history which have no tool message yet,
+// e.g. calls left by a session that ended while they were awaiting approval.
+// Only the trailing block of tool calls and tool messages is inspected
+func (m *Messages) PendingToolCalls() []EventToolCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := len(m.Events)
+	answered := make(map[string]bool)
+scan:
+	for ; start > 0; start-- {
+		switch event := m.Events[start-1].(type) {
+		case EventToolCall:
+		case EventToolMessage:
+			answered[event.CallID] = true
+		default:
+			break scan
+		}
+	}
+
+	var pending []EventToolCall
+	for _, event := range m.Events[start:] {
+		if call, ok := event.(EventToolCall); ok && !answered[call.CallID] {
+			pending = append(pending, call)
+		}
+	}
+	return pending
+}
+
+// Resume starts a session which first answers the tool calls left unanswered at the end of the history
+// (see Messages.PendingToolCalls), so approvals survive a restart of the process.
+//
+// The pending calls go through the approval policy and are sent as EventToolCall followed by
+// EventCompletionEnded, exactly like the calls of a regular completion. Their tool messages are
+// added to the history before the next completion starts.
+// Without pending calls Resume behaves like Session
+func (c *Chat) Resume(ctx context.Context, client Client) <-chan StreamEvent {
+	return c.session(ctx, client, sessionOptions{resume: true})
+}
+
+// resumeToolCalls re-surfaces the pending tool calls of the history and executes them.
+// It returns false if the session must end
+func (c *Chat) resumeToolCalls(ctx context.Context, state *sessionState) bool {
+	pending := c.Messages.PendingToolCalls()
+	if len(pending) == 0 {
+		return true
+	}
+
+	state.reset()
+	for _, call := range pending {
+		// the resolution state of the stored call belongs to the previous session
+		call.resolution = newResolution()
+		call.detached = false
+		state.addToolCall(call)
+		if !state.flushLastToolCall() {
+			return false
+		}
+	}
+
+	return c.awaitToolCalls(ctx, state)
+}
@@ -0,0 +1,110 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func TestMessages_PendingToolCalls(t *testing.T) {
+	m := NewMessages()
+	m.AddEvent(NewEventUserMessage("hi"))
+	m.AddEvent(NewEventToolCall("old", "tool", `{}`))
+	m.AddEvent(NewEventAssistantMessage("checking"))
+	m.AddEvent(NewEventToolCall("call-1", "tool", `{}`))
+	m.AddEvent(NewEventToolCall("call-2", "tool", `{}`))
+	m.AddEvent(NewEventToolMessage("call-1", "result", true))
+
+	pending := m.PendingToolCalls()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "call-2", pending[0].CallID)
+
+	m.AddEvent(NewEventToolMessage("call-2", "result", true))
+	assert.Empty(t, m.PendingToolCalls())
+
+	m.AddEvent(NewEventAssistantMessage("done"))
+	assert.Empty(t, m.PendingToolCalls())
+}
+
+// restoredChat returns a chat whose history was persisted while call-1 was awaiting approval
+func restoredChat(t *testing.T) *Chat {
+	t.Helper()
+
+	c, _ := newToolChat(t, "tool", "result")
+
+	for _, event := range []StreamEvent{
+		NewEventUserMessage("hi"),
+		NewEventToolCall("call-1", "tool", `{}`),
+	} {
+		data, err := MarshalEvent(event)
+		require.NoError(t, err)
+		restored, err := UnmarshalEvent(data)
+		require.NoError(t, err)
+		c.AppendEvent(restored)
+	}
+	return c
+}
+
+func TestResume_ResurfacesPendingToolCalls(t *testing.T) {
+	c := restoredChat(t)
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToken("answer")},
+	})
+
+	var received []StreamEvent
+	for event := range c.Resume(context.Background(), mockClient) {
+		received = append(received, event)
+		if tc, ok := event.(EventToolCall); ok {
+			assert.False(t, tc.Resolved())
+			assert.NoError(t, tc.Resolve(true))
+		}
+	}
+
+	require.Greater(t, len(received), 2)
+	assert.IsType(t, EventToolCall{}, received[0], "pending calls are surfaced before the completion")
+	require.IsType(t, EventCompletionEnded{}, received[1])
+	assert.Len(t, received[1].(EventCompletionEnded).ToolCalls, 1)
+
+	// the tool message must be in the history synced for the next completion
+	require.NotNil(t, mockClient.SyncedChat)
+	synced := withoutMetadata(mockClient.SyncedChat.Messages.Snapshot())
+	assert.Equal(t, NewEventToolMessage("call-1", "result", true), synced[2])
+
+	messages := withoutMetadata(c.Messages.Snapshot())
+	assert.Equal(t, NewEventAssistantMessage("answer"), messages[len(messages)-1])
+	assert.Empty(t, c.Messages.PendingToolCalls())
+}
+
+func TestResume_ApprovalPolicy(t *testing.T) {
+	c := restoredChat(t)
+	c.ApprovalPolicy = DenyList("not anymore", "tool")
+	mockClient := NewMultiRoundMockClient(nil)
+
+	for event := range c.Resume(context.Background(), mockClient) {
+		if tc, ok := event.(EventToolCall); ok {
+			assert.True(t, tc.Resolved(), "policy decides before the consumer")
+		}
+	}
+
+	assert.Contains(t, withoutMetadata(c.Messages.Snapshot()), NewEventToolMessage("call-1", "not anymore", false))
+}
+
+func TestResume_WithoutPendingCalls(t *testing.T) {
+	c := &Chat{Messages: NewMessages(), Tools: tools.NewTools()}
+	c.AppendEvent(NewEventUserMessage("hi"))
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToken("hello")},
+	})
+
+	var received []StreamEvent
+	for event := range c.Resume(context.Background(), mockClient) {
+		received = append(received, event)
+	}
+
+	require.NotEmpty(t, received)
+	assert.Equal(t, NewEventCompletionStart(), received[0])
+	assert.Equal(t, NewEventAssistantMessage("hello"), withoutMetadata(c.Messages.Snapshot())[1])
+}
@@ -0,0 +1,79 @@
+package chat
+
+import "context"
+
+// SendResult is the outcome of Chat.Send
+type SendResult struct {
+	// Text is the content of the last assistant message added by the session
+	Text string
+	// ToolCalls are the tool calls executed during the session, with the arguments they were executed with
+	ToolCalls []EventToolCall
+	// Usage is the token usage of all completions, as reported by the connector with EventUsage
+	Usage Usage
+}
+
+type sendConfig struct {
+	sender Sender
+	policy ApprovalPolicy
+}
+
+// SendOption configures Chat.Send
+type SendOption func(cfg *sendConfig)
+
+// WithSender sends the message on behalf of the sender (default: SenderUser)
+func WithSender(sender Sender) SendOption {
+	return func(cfg *sendConfig) {
+		cfg.sender = sender
+	}
+}
+
+// WithApprovalPolicy decides on the tool calls of the session instead of Chat.ApprovalPolicy
+func WithApprovalPolicy(policy ApprovalPolicy) SendOption {
+	return func(cfg *sendConfig) {
+		cfg.policy = policy
+	}
+}
+
+// Send adds the message to the chat and blocks until the session ends.
+// There's nobody to ask for approvals, so tool calls deferred by the approval policy are declined.
+// The error is the first EventError of the session (see SessionResult.Err)
+func (c *Chat) Send(ctx context.Context, client Client, text string, opts ...SendOption) (SendResult, error) {
+	cfg := sendConfig{sender: SenderUser{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if err := c.AddMessage(cfg.sender, text); err != nil {
+		return SendResult{}, err
+	}
+
+	h := c.start(ctx, client, sessionOptions{policy: cfg.policy})
+
+	executed := make(map[string]bool)
+	for event := range h.Events() {
+		switch e := event.(type) {
+		case EventToolCall:
+			if !e.Resolved() {
+				e.Resolve(false)
+			}
+		case EventToolCallResolved:
+			if e.Accepted && !e.Supplied {
+				executed[e.CallID] = true
+			}
+		}
+	}
+
+	result := h.Result()
+	sent := SendResult{Usage: result.Usage}
+	for _, message := range result.Messages {
+		switch m := message.(type) {
+		case EventAssistantMessage:
+			sent.Text = m.Content
+		case EventToolCall:
+			if executed[m.CallID] {
+				sent.ToolCalls = append(sent.ToolCalls, m)
+			}
+		}
+	}
+	return sent, result.Err
+}
@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
+)
+
+func TestChat_Send(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	other, err := tools.NewTool("other", "", func(input map[string]string) (string, error) {
+		return "other result", nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Tools.Add(other))
+
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{
+			NewEventToolCall("call-1", "tool", `{}`),
+			NewEventToolCall("call-2", "other", `{}`),
+			NewEventUsage(Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}),
+		},
+		{
+			NewEventToken("The answer is 42"),
+			NewEventUsage(Usage{PromptTokens: 20, CompletionTokens: 5, TotalTokens: 25}),
+		},
+	})
+
+	result, err := c.Send(context.Background(), mockClient, "what is the answer?", WithApprovalPolicy(AllowList("tool")))
+	require.NoError(t, err)
+
+	assert.Equal(t, "The answer is 42", result.Text)
+	assert.Equal(t, Usage{PromptTokens: 30, CompletionTokens: 7, TotalTokens: 37}, result.Usage)
+	require.Len(t, result.ToolCalls, 1)
+	assert.Equal(t, "call-1", result.ToolCalls[0].CallID)
+
+	messages := withoutMetadata(c.Messages.Snapshot())
+	assert.Equal(t, NewEventUserMessage("what is the answer?"), messages[0])
+	assert.Contains(t, messages, NewEventToolMessage("call-2", DefaultDeclinedToolMessage, false), "deferred calls are declined")
+	assert.Nil(t, c.ApprovalPolicy, "the policy applies only to the session")
+}
+
+func TestChat_SendWithSender(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{{NewEventToken("ok")}})
+
+	result, err := c.Send(context.Background(), mockClient, "be brief", WithSender(SenderSystem{}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "ok", result.Text)
+	assert.Equal(t, NewEventSystemMessage("be brief"), withoutMetadata(c.Messages.Snapshot())[0])
+}
+
+func TestChat_SendError(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	streamErr := errors.New("stream failed")
+	mockClient := NewMockClient()
+	mockClient.SetStreamingEvents([]StreamEvent{})
+	mockClient.SetStreamingError(streamErr)
+
+	_, err := c.Send(context.Background(), mockClient, "hi")
+	assert.ErrorIs(t, err, streamErr)
+}
@@ -40,6 +40,8 @@ func UnmarshalEvent(data []byte) (StreamEvent, error) {
 		return unmarshalPayload[EventToolCall](env.Payload)
 	case eventToolCallResolved:
 		return unmarshalPayload[EventToolCallResolved](env.Payload)
+	case eventApprovalExpired:
+		return unmarshalPayload[EventApprovalExpired](env.Payload)
 	case eventToolCallToken:
 		return unmarshalPayload[EventToolCallToken](env.Payload)
 	case eventRefusal:
@@ -48,6 +50,14 @@ func UnmarshalEvent(data []byte) (StreamEvent, error) {
 		return unmarshalPayload[EventCompletionStart](env.Payload)
 	case eventCompletionEnded:
 		return unmarshalPayload[EventCompletionEnded](env.Payload)
+	case eventLimitReached:
+		return unmarshalPayload[EventLimitReached](env.Payload)
+	case eventUsage:
+		return unmarshalPayload[EventUsage](env.Payload)
+	case eventInterrupted:
+		return unmarshalPayload[EventInterrupted](env.Payload)
+	case eventGeneration:
+		return unmarshalPayload[EventGeneration](env.Payload)
 	case eventUserMessage:
 		return unmarshalPayload[EventUserMessage](env.Payload)
 	case eventAssistantMessage:
@@ -3,9 +3,11 @@ package chat
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/x2d7/interlude/chat/tools"
 )
 
 func TestMarshalUnmarshalEvent_RoundTrip(t *testing.T) {
@@ -181,6 +183,160 @@ func TestMarshalUnmarshalEvent_RoundTrip(t *testing.T) {
 				assert.Equal(t, "tool_b", e.ToolCalls[1].Name)
 				assert.Equal(t, `{"y":2}`, e.ToolCalls[1].Content)
 			},
+		},
+		{
+			name:  "EventLimitReached",
+			event: NewEventLimitReached(LimitToolCallLoop, 3),
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventLimitReached)
+				require.True(t, ok)
+				assert.Equal(t, LimitToolCallLoop, e.Limit)
+				assert.Equal(t, 3, e.Rounds)
+			},
+		},
+		{
+			name: "EventToolCall_ApprovalDetails",
+			event: EventToolCall{
+				EventBase: EventBase{Content: `{}`},
+				CallID:    "call-1",
+				Name:      "write_file",
+				Risk:      tools.RiskHigh,
+				Prompt:    "Write the file?",
+			},
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventToolCall)
+				require.True(t, ok)
+				assert.Equal(t, tools.RiskHigh, e.Risk)
+				assert.Equal(t, "Write the file?", e.Prompt)
+			},
+		},
+		{
+			name:  "EventApprovalExpired",
+			event: NewEventApprovalExpired("call-1", TimeoutEndSession),
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventApprovalExpired)
+				require.True(t, ok)
+				assert.Equal(t, "call-1", e.CallID)
+				assert.Equal(t, TimeoutEndSession, e.Action)
+			},
+		},
+		{
+			name: "EventToolMessage_Supplied",
+			event: EventToolMessage{
+				EventBase: EventBase{Content: "manual result"},
+				CallID:    "call-1",
+				Success:   true,
+				Supplied:  true,
+			},
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventToolMessage)
+				require.True(t, ok)
+				assert.Equal(t, "manual result", e.Content)
+				assert.True(t, e.Supplied)
+			},
+		},
+		{
+			name: "EventToolCall_ResolutionState",
+			event: func() StreamEvent {
+				call := NewEventToolCall("call-1", "my_tool", `{}`)
+				call.Resolve(true)
+				return call
+			}(),
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventToolCall)
+				require.True(t, ok)
+				assert.True(t, e.Resolved())
+			},
+		},
+		{
+			name:  "EventToolCall_Detached",
+			event: NewEventToolCall("call-1", "my_tool", `{}`),
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventToolCall)
+				require.True(t, ok)
+				assert.False(t, e.Resolved())
+				assert.ErrorIs(t, e.Resolve(true), ErrToolCallDetached)
+			},
+		},
+		{
+			name:  "EventUsage",
+			event: NewEventUsage(Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, ReasoningTokens: 2}),
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventUsage)
+				require.True(t, ok)
+				assert.Equal(t, Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, ReasoningTokens: 2}, e.Usage)
+			},
+		},
+		{
+			name:  "EventInterrupted",
+			event: NewEventInterrupted(),
+			check: func(t *testing.T, result StreamEvent) {
+				_, ok := result.(EventInterrupted)
+				require.True(t, ok)
+			},
+		},
+		{
+			name: "EventAssistantMessage_Partial",
+			event: EventAssistantMessage{
+				EventBase: EventBase{Content: "Once upon"},
+				Partial:   PartialInterrupted,
+			},
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventAssistantMessage)
+				require.True(t, ok)
+				assert.Equal(t, "Once upon", e.Content)
+				assert.Equal(t, PartialInterrupted, e.Partial)
+			},
+		},
+		{
+			name:  "EventGeneration",
+			event: NewEventGeneration("gpt-4o", "chatcmpl-1"),
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventGeneration)
+				require.True(t, ok)
+				assert.Equal(t, NewEventGeneration("gpt-4o", "chatcmpl-1"), e)
+			},
+		},
+		{
+			name: "EventAssistantMessage_Metadata",
+			event: EventAssistantMessage{
+				EventBase: EventBase{Content: "hello"},
+				Meta: Metadata{
+					ID:           "msg_1",
+					CreatedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+					Model:        "gpt-4o",
+					GenerationID: "chatcmpl-1",
+					Latency:      1500 * time.Millisecond,
+					Usage:        Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+				},
+			},
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventAssistantMessage)
+				require.True(t, ok)
+				assert.Equal(t, "hello", e.Content)
+				assert.Equal(t, Metadata{
+					ID:           "msg_1",
+					CreatedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+					Model:        "gpt-4o",
+					GenerationID: "chatcmpl-1",
+					Latency:      1500 * time.Millisecond,
+					Usage:        Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+				}, e.Meta)
+			},
+		},
+		{
+			name: "EventToolCall_Metadata",
+			event: EventToolCall{
+				EventBase: EventBase{Content: `{}`},
+				CallID:    "call-1",
+				Name:      "tool",
+				Meta:      Metadata{ID: "msg_2", Model: "gpt-4o"},
+			},
+			check: func(t *testing.T, result StreamEvent) {
+				e, ok := result.(EventToolCall)
+				require.True(t, ok)
+				assert.Equal(t, Metadata{ID: "msg_2", Model: "gpt-4o"}, e.Meta)
+			},
 		}}
 
 	for _, tt := range tests {
@@ -0,0 +1,95 @@
+package chat
+
+import "sync"
+
+// steeringQueue holds the user messages enqueued into a running session (see SessionHandle.Steer)
+type steeringQueue struct {
+	mu       sync.Mutex
+	messages []string
+	closed   bool
+}
+
+func newSteeringQueue() *steeringQueue {
+	return &steeringQueue{}
+}
+
+func (q *steeringQueue) push(text string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrSessionEnded
+	}
+	q.messages = append(q.messages, text)
+	return nil
+}
+
+func (q *steeringQueue) drain() []string {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	messages := q.messages
+	q.messages = nil
+	return messages
+}
+
+func (q *steeringQueue) pending() bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.messages) != 0
+}
+
+// close rejects further messages, the messages which were not applied yet stay in the queue
+// (see Chat.commitSteering)
+func (q *steeringQueue) close() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+}
+
+// applySteering adds the enqueued user messages to the chat and sends them as EventUserMessage
+func (c *Chat) applySteering(state *sessionState) bool {
+	for _, text := range state.steering.drain() {
+		// stamped before it's sent, so the consumer sees the stored message
+		message := stamp(NewEventUserMessage(text))
+		c.AppendEvent(message)
+		if !state.send(message) {
+			return false
+		}
+	}
+	return true
+}
+
+// commitSteering adds the messages which were enqueued too late for the ended session to the end of the chat,
+// so the next session answers them. They can't follow tool calls left unanswered in the history (see Chat.Resume),
+// in such case they stay in the queue and are reported with SessionResult.Steering.
+// The queue must be closed already, so no message is enqueued after the check
+func (c *Chat) commitSteering(state *sessionState) {
+	if len(c.Messages.PendingToolCalls()) != 0 {
+		return
+	}
+	for _, text := range state.steering.drain() {
+		c.AppendEvent(NewEventUserMessage(text))
+	}
+}
+
+// Steer enqueues a user message into the running session, e.g. to correct the agent without waiting
+// for it to finish. The message is added to the chat at the next round boundary (after the tool results
+// of the current round, before the next completion) and sent as EventUserMessage when it's applied.
+// A session which would end after the current completion runs one more round for the enqueued messages.
+//
+// A message accepted with nil is never dropped. If the session ends before it's applied (e.g. because of an error,
+// a limit or a race with the last completion), it's added to the end of the chat without EventUserMessage,
+// so the next session answers it. When the session ends with unanswered tool calls (see Chat.Resume)
+// it's reported with SessionResult.Steering instead.
+// It returns ErrSessionEnded if the session is over
+func (h *SessionHandle) Steer(text string) error {
+	return h.steering.push(text)
+}
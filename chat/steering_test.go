@@ -0,0 +1,164 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHandle_SteerAfterToolResults(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	mockClient := NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+		{NewEventToken("using staging")},
+	})
+
+	h := c.Start(context.Background(), mockClient)
+
+	var received []StreamEvent
+	for event := range h.Events() {
+		received = append(received, event)
+		if tc, ok := event.(EventToolCall); ok {
+			require.NoError(t, h.Steer("actually, use the staging database"))
+			require.NoError(t, tc.Resolve(true))
+		}
+	}
+	require.NoError(t, h.Wait())
+
+	steer := NewEventUserMessage("actually, use the staging database")
+
+	// applied between the tool results and the next completion
+	index := -1
+	for i, event := range received {
+		if stripMetadata(event) == StreamEvent(steer) {
+			index = i
+		}
+	}
+	require.NotEqual(t, -1, index, "applied messages are sent as events")
+	assert.IsType(t, EventToolMessage{}, received[index-1])
+	assert.Equal(t, NewEventCompletionStart(), received[index+1])
+
+	messages := withoutMetadata(c.Messages.Snapshot())
+	require.Len(t, messages, 4)
+	assert.Equal(t, NewEventToolMessage("call-1", "result", true), messages[1])
+	assert.Equal(t, steer, messages[2])
+	assert.Equal(t, NewEventAssistantMessage("using staging"), messages[3])
+}
+
+// gatedStream emits its events and ends once the gate is closed
+type gatedStream struct {
+	*MockStream
+	gate <-chan struct{}
+}
+
+func (s *gatedStream) Next(ctx context.Context) bool {
+	if s.MockStream.Next(ctx) {
+		return true
+	}
+	select {
+	case <-s.gate:
+	case <-ctx.Done():
+	}
+	return false
+}
+
+// gatedClient holds the first completion open until the gate is closed
+type gatedClient struct {
+	*MultiRoundMockClient
+	gate chan struct{}
+	once bool
+}
+
+func (c *gatedClient) NewStreaming(ctx context.Context) Stream[StreamEvent] {
+	stream := c.MultiRoundMockClient.NewStreaming(ctx).(*MockStream)
+	if c.once {
+		return stream
+	}
+	c.once = true
+	return &gatedStream{MockStream: stream, gate: c.gate}
+}
+
+func (c *gatedClient) SyncInput(chat *Chat) Client {
+	c.MultiRoundMockClient.SyncInput(chat)
+	return c
+}
+
+func TestSessionHandle_SteerRunsAnotherRound(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	client := &gatedClient{
+		MultiRoundMockClient: NewMultiRoundMockClient([][]StreamEvent{
+			{NewEventToken("first")},
+			{NewEventToken("second")},
+		}),
+		gate: make(chan struct{}),
+	}
+
+	h := c.Start(context.Background(), client)
+	for event := range h.Events() {
+		if token, ok := event.(EventToken); ok && token.Content == "first" {
+			require.NoError(t, h.Steer("one more thing"))
+			close(client.gate)
+		}
+	}
+
+	result := h.Result()
+	assert.Equal(t, 2, result.Rounds)
+	assert.Equal(t, []StreamEvent{
+		NewEventAssistantMessage("first"),
+		NewEventUserMessage("one more thing"),
+		NewEventAssistantMessage("second"),
+	}, withoutMetadata(c.Messages.Snapshot()))
+}
+
+func TestSessionHandle_SteerEndedSession(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	h := c.Start(context.Background(), NewMultiRoundMockClient(nil))
+	for range h.Events() {
+	}
+	<-h.Done()
+
+	assert.ErrorIs(t, h.Steer("too late"), ErrSessionEnded)
+}
+
+func TestSessionHandle_SteerBeforeSessionFailed(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	h := c.Start(context.Background(), &hangingClient{events: []StreamEvent{NewEventToken("working")}})
+
+	for event := range h.Events() {
+		if _, ok := event.(EventToken); ok {
+			require.NoError(t, h.Steer("never mind"))
+			h.Cancel()
+		}
+	}
+	<-h.Done()
+
+	// the session ended before the next round, the message is kept for the next session
+	assert.ErrorIs(t, h.Result().Err, context.Canceled)
+	assert.Empty(t, h.Result().Steering)
+	messages := withoutMetadata(c.Messages.Snapshot())
+	require.NotEmpty(t, messages)
+	assert.Equal(t, NewEventUserMessage("never mind"), messages[len(messages)-1])
+}
+
+func TestSessionHandle_SteerWithUnansweredToolCalls(t *testing.T) {
+	c, _ := newToolChat(t, "tool", "result")
+	h := c.Start(context.Background(), NewMultiRoundMockClient([][]StreamEvent{
+		{NewEventToolCall("call-1", "tool", `{}`)},
+	}))
+
+	for event := range h.Events() {
+		if _, ok := event.(EventCompletionEnded); ok {
+			require.NoError(t, h.Steer("use the staging database"))
+			h.Interrupt()
+		}
+	}
+	<-h.Done()
+
+	// the message can't follow the unanswered call, so it's reported instead of being dropped
+	assert.Equal(t, []string{"use the staging database"}, h.Result().Steering)
+	pending := c.Messages.PendingToolCalls()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "call-1", pending[0].CallID)
+}
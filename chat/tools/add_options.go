@@ -1,5 +1,7 @@
 package tools
 
+import "time"
+
 type AddOption func(*toolAddConfig)
 
 type toolAddConfig struct {
@@ -9,6 +11,14 @@ type toolAddConfig struct {
 	startIncrement int
 
 	changedStartIncrement bool
+
+	readOnly bool
+	approval approvalMode
+	risk     RiskLevel
+	prompt   PromptBuilder
+
+	timeout         time.Duration
+	approvalTimeout time.Duration
 }
 
 func WithOverrideName(name string) AddOption {
@@ -29,3 +39,53 @@ func WithStartIncrement(start int) AddOption {
 		c.changedStartIncrement = true
 	}
 }
+
+// WithReadOnly marks the tool as read-only: it doesn't change anything outside of the conversation.
+// Approval policies may use it to run such tools without asking the user
+func WithReadOnly() AddOption {
+	return func(c *toolAddConfig) {
+		c.readOnly = true
+	}
+}
+
+// WithRequiresApproval makes every call of the tool wait for the user, even if an approval policy would approve it
+func WithRequiresApproval() AddOption {
+	return func(c *toolAddConfig) {
+		c.approval = approvalRequired
+	}
+}
+
+// WithAutoApprove executes the tool without asking the user
+func WithAutoApprove() AddOption {
+	return func(c *toolAddConfig) {
+		c.approval = approvalAuto
+	}
+}
+
+func WithRiskLevel(level RiskLevel) AddOption {
+	return func(c *toolAddConfig) {
+		c.risk = level
+	}
+}
+
+// WithApprovalPrompt sets the builder of the human-readable text shown to the user when the tool call needs approval
+func WithApprovalPrompt(builder PromptBuilder) AddOption {
+	return func(c *toolAddConfig) {
+		c.prompt = builder
+	}
+}
+
+// WithTimeout limits the execution time of every call of the tool.
+// The context passed to tools created with NewToolCtx is cancelled when the timeout expires
+func WithTimeout(timeout time.Duration) AddOption {
+	return func(c *toolAddConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithApprovalTimeout overrides Chat.ApprovalTimeout for the calls of the tool
+func WithApprovalTimeout(timeout time.Duration) AddOption {
+	return func(c *toolAddConfig) {
+		c.approvalTimeout = timeout
+	}
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+)
+
+// RiskLevel describes how dangerous the execution of a tool is
+type RiskLevel uint
+
+const (
+	RiskUnspecified RiskLevel = iota
+	RiskLow
+	RiskMedium
+	RiskHigh
+)
+
+var riskLevelNames = map[RiskLevel]string{
+	RiskUnspecified: "unspecified",
+	RiskLow:         "low",
+	RiskMedium:      "medium",
+	RiskHigh:        "high",
+}
+
+func (r RiskLevel) String() string {
+	if name, ok := riskLevelNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("RiskLevel(%d)", uint(r))
+}
+
+func (r RiskLevel) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *RiskLevel) UnmarshalText(text []byte) error {
+	for level, name := range riskLevelNames {
+		if name == string(text) {
+			*r = level
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown risk level: %q", text)
+}
+
+// approvalMode describes whether the tool calls need the approval of the user
+type approvalMode uint
+
+const (
+	approvalDefault approvalMode = iota
+	approvalRequired
+	approvalAuto
+)
+
+// PromptBuilder builds a human-readable approval prompt from raw tool call arguments
+type PromptBuilder func(arguments string) string
+
+// RequiresApproval reports whether the tool was added with WithRequiresApproval
+func (t *tool) RequiresApproval() bool {
+	return t.approval == approvalRequired
+}
+
+// AutoApprove reports whether the tool was added with WithAutoApprove
+func (t *tool) AutoApprove() bool {
+	return t.approval == approvalAuto
+}
+
+// Risk returns the risk level set by WithRiskLevel
+func (t *tool) Risk() RiskLevel {
+	return t.risk
+}
+
+// ApprovalPrompt builds the approval prompt for the given arguments.
+// Returns an empty string if the tool was added without WithApprovalPrompt
+func (t *tool) ApprovalPrompt(arguments string) string {
+	if t.prompt == nil {
+		return ""
+	}
+	return t.prompt(arguments)
+}
+
+// ApprovalTimeout returns the approval timeout set by WithApprovalTimeout
+func (t *tool) ApprovalTimeout() time.Duration {
+	return t.approvalTimeout
+}
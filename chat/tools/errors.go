@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrEmptyToolID       = errors.New("tool ID cannot be empty")
 	ErrToolAlreadyExists = errors.New("tool with this ID already exists")
+	ErrToolNotFound      = errors.New("tool not found")
+	ErrInvalidArguments  = errors.New("invalid tool arguments")
 )
@@ -1,12 +1,23 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 func NewTool[T any](name, description string, f func(T) (string, error)) (tool, error) {
+	return NewToolCtx(name, description, func(_ context.Context, input T) (string, error) {
+		return f(input)
+	})
+}
+
+// NewToolCtx creates a tool whose function observes the context of the session.
+// The context is cancelled when the session is cancelled or when the tool timeout expires (see WithTimeout)
+func NewToolCtx[T any](name, description string, f func(context.Context, T) (string, error)) (tool, error) {
 	inputType := ensureInputStructType[T]()
 
 	var extract func(reflect.Value) T
@@ -16,12 +27,12 @@ func NewTool[T any](name, description string, f func(T) (string, error)) (tool,
 		extract = func(v reflect.Value) T { return v.Field(0).Interface().(T) }
 	}
 
-	wrapper := func(input string) (string, error) {
+	wrapper := func(ctx context.Context, input string) (string, error) {
 		ptr := reflect.New(inputType)
 		if err := json.Unmarshal([]byte(input), ptr.Interface()); err != nil {
 			return "", fmt.Errorf("unmarshal into %v: %w", inputType, err)
 		}
-		return f(extract(ptr.Elem()))
+		return f(ctx, extract(ptr.Elem()))
 	}
 
 	t := tool{
@@ -41,18 +52,58 @@ func NewTool[T any](name, description string, f func(T) (string, error)) (tool,
 	return t, nil
 }
 
-func (t *Tools) Execute(name string, arguments string) (result string, ok bool) {
-	for _, tool := range t.Snapshot() {
-		if tool.Id != name {
-			continue
-		}
+// Execute runs the tool with the given arguments.
+//
+// Execution stops waiting for the tool when ctx is done or the tool timeout expires,
+// even if the tool function itself doesn't observe the context
+func (t *Tools) Execute(ctx context.Context, name string, arguments string) (result string, ok bool) {
+	tool, found := t.Get(name)
+	if !found {
+		return fmt.Sprintf("error: tool %q not found", name), false
+	}
+
+	if tool.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, tool.timeout, errToolTimeout)
+		defer cancel()
+	}
+
+	// the function may ignore the context, so it's not started at all once the context is done
+	if ctx.Err() != nil {
+		return stoppedMessage(ctx, name, tool.timeout), false
+	}
+
+	type execution struct {
+		result string
+		err    error
+	}
+
+	// buffered, so the function can finish after Execute stopped waiting for it
+	done := make(chan execution, 1)
+	go func() {
+		result, err := tool.function(ctx, arguments)
+		done <- execution{result: result, err: err}
+	}()
 
-		result, err := tool.function(arguments)
-		if err != nil {
-			return err.Error(), false
+	select {
+	case e := <-done:
+		if e.err != nil {
+			return e.err.Error(), false
 		}
-		return result, true
+		return e.result, true
+	case <-ctx.Done():
+		return stoppedMessage(ctx, name, tool.timeout), false
 	}
+}
+
+// errToolTimeout is the cause of the context of a tool whose own timeout expired (see WithTimeout)
+var errToolTimeout = errors.New("tool timeout expired")
 
-	return fmt.Sprintf("error: tool %q not found", name), false
+// stoppedMessage describes why the execution of the tool was stopped by the context.
+// A deadline of the parent context is a cancellation, only the timeout of the tool is reported as such
+func stoppedMessage(ctx context.Context, name string, timeout time.Duration) string {
+	if errors.Is(context.Cause(ctx), errToolTimeout) {
+		return fmt.Sprintf("error: tool %q timed out after %s", name, timeout)
+	}
+	return fmt.Sprintf("error: tool %q cancelled: %v", name, ctx.Err())
 }
@@ -1,10 +1,13 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // ============================================================================
@@ -83,7 +86,7 @@ func TestNewTool_WithStruct(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("test_struct", `{"name": "John", "age": 30}`)
+	result, ok := tools.Execute(context.Background(), "test_struct", `{"name": "John", "age": 30}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -131,7 +134,7 @@ func TestNewTool_WithPrimitive(t *testing.T) {
 	}
 
 	// Try to execute with valid input
-	result, ok := tools.Execute("test_primitive", `{"input": "hello"}`)
+	result, ok := tools.Execute(context.Background(), "test_primitive", `{"input": "hello"}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -173,7 +176,7 @@ func TestNewTool_NestedStruct(t *testing.T) {
 	}
 
 	// Try to execute with valid input
-	result, ok := tools.Execute("test_nested", `{"user": {"name": "John", "age": 30}, "active": true}`)
+	result, ok := tools.Execute(context.Background(), "test_nested", `{"user": {"name": "John", "age": 30}, "active": true}`)
 	t.Log("result: ", result)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
@@ -217,7 +220,7 @@ func TestNewTool_EmbeddedStruct(t *testing.T) {
 	}
 
 	// Try to execute with valid input
-	result, ok := tools.Execute("test_embedded", `{"name": "John", "age": 30}`)
+	result, ok := tools.Execute(context.Background(), "test_embedded", `{"name": "John", "age": 30}`)
 	t.Log("result: ", result)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
@@ -266,7 +269,7 @@ func TestNewTool_PrimitiveWithMapPrimitive(t *testing.T) {
 	}
 
 	// Try to execute with valid input
-	result, ok := tools.Execute("test_map_primitive", `{"scores": {"a": 1, "b": 2}}`)
+	result, ok := tools.Execute(context.Background(), "test_map_primitive", `{"scores": {"a": 1, "b": 2}}`)
 	t.Log("result: ", result)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
@@ -310,7 +313,7 @@ func TestNewTool_PrimitiveWithMapStruct(t *testing.T) {
 	}
 
 	// Try to execute with valid input
-	result, ok := tools.Execute("test_map_struct", `{"users": {"alice": {"age": 25}, "bob": {"age": 30}}}`)
+	result, ok := tools.Execute(context.Background(), "test_map_struct", `{"users": {"alice": {"age": 25}, "bob": {"age": 30}}}`)
 	t.Log("result: ", result)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
@@ -354,7 +357,7 @@ func TestNewTool_PrimitiveWithSlicePrimitive(t *testing.T) {
 	}
 
 	// Try to execute with valid input
-	result, ok := tools.Execute("test_slice_primitive", `{"ids": [1, 2, 3]}`)
+	result, ok := tools.Execute(context.Background(), "test_slice_primitive", `{"ids": [1, 2, 3]}`)
 	t.Log("result: ", result)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
@@ -397,7 +400,7 @@ func TestNewTool_PrimitiveWithSliceStruct(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("test_slice_struct", `{"items": [{"name": "Alice", "age": 25}, {"name": "Bob", "age": 30}]}`)
+	result, ok := tools.Execute(context.Background(), "test_slice_struct", `{"items": [{"name": "Alice", "age": 25}, {"name": "Bob", "age": 30}]}`)
 	t.Log("result: ", result)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
@@ -435,7 +438,7 @@ func TestNewTool_PrimitiveWithMap(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("test_map", `{"data": {"key": "value"}}`)
+	result, ok := tools.Execute(context.Background(), "test_map", `{"data": {"key": "value"}}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -483,7 +486,7 @@ func TestNewTool_RecursiveStruct(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("test_recursive", `{"value": 42, "child": {"value": 100}}`)
+	result, ok := tools.Execute(context.Background(), "test_recursive", `{"value": 42, "child": {"value": 100}}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -515,7 +518,7 @@ func TestExecute_NormalCall(t *testing.T) {
 
 	// Execute the tool
 	input := `{"name": "John", "age": 30}`
-	result, ok := tools.Execute("greet", input)
+	result, ok := tools.Execute(context.Background(), "greet", input)
 
 	if !ok {
 		t.Errorf("Execute() ok = false, want true")
@@ -539,7 +542,7 @@ func TestExecute_NonExistentTool(t *testing.T) {
 	tools.Add(tool)
 
 	// Execute non-existent tool
-	result, ok := tools.Execute("nonexistent", `{}`)
+	result, ok := tools.Execute(context.Background(), "nonexistent", `{}`)
 
 	if ok {
 		t.Errorf("Execute() ok = true, want false")
@@ -564,7 +567,7 @@ func TestExecute_ToolReturnsError(t *testing.T) {
 
 	// Execute tool that returns error
 	input := `{"name": "Test", "age": 25}`
-	result, ok := tools.Execute("error_tool", input)
+	result, ok := tools.Execute(context.Background(), "error_tool", input)
 
 	if ok {
 		t.Errorf("Execute() ok = true, want false")
@@ -592,7 +595,7 @@ func TestExecute_MalformedJSON(t *testing.T) {
 	tools.Add(tool)
 
 	// Execute with malformed JSON
-	result, ok := tools.Execute("json_test", `{invalid json}`)
+	result, ok := tools.Execute(context.Background(), "json_test", `{invalid json}`)
 
 	if ok {
 		t.Errorf("Execute() ok = true, want false for malformed JSON")
@@ -617,7 +620,7 @@ func TestExecute_EmptyArguments(t *testing.T) {
 	tools.Add(tool)
 
 	// Execute with empty string
-	result, ok := tools.Execute("empty_args", "")
+	result, ok := tools.Execute(context.Background(), "empty_args", "")
 
 	// Empty string should produce unmarshal error or zero values
 	if ok && result == "" {
@@ -638,7 +641,7 @@ func TestExecute_TypeMismatch(t *testing.T) {
 	tools.Add(tool)
 
 	// Execute with wrong type (number instead of string)
-	result, ok := tools.Execute("type_test", `{"name": 123, "age": "not-a-number"}`)
+	result, ok := tools.Execute(context.Background(), "type_test", `{"name": 123, "age": "not-a-number"}`)
 
 	if ok {
 		t.Errorf("Execute() ok = true, want false for type mismatch")
@@ -662,7 +665,7 @@ func TestExecute_MissingRequiredFields(t *testing.T) {
 	tools.Add(tool)
 
 	// Execute with empty JSON object (missing required fields)
-	result, ok := tools.Execute("required_test", `{}`)
+	result, ok := tools.Execute(context.Background(), "required_test", `{}`)
 
 	// This should succeed with zero values, not error
 	if !ok {
@@ -685,7 +688,7 @@ func TestExecute_PartialFields(t *testing.T) {
 	tools.Add(tool)
 
 	// Execute with only some fields
-	result, ok := tools.Execute("partial_test", `{"name": "John"}`)
+	result, ok := tools.Execute(context.Background(), "partial_test", `{"name": "John"}`)
 
 	if !ok {
 		t.Errorf("Execute() ok = false, want true for partial fields")
@@ -774,7 +777,7 @@ func TestNewTool_PointerType(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("pointer_test", `{"name": "John"}`)
+	result, ok := tools.Execute(context.Background(), "pointer_test", `{"name": "John"}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -813,7 +816,7 @@ func TestNewTool_InterfaceType(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("interface_test", `{"data": "test"}`)
+	result, ok := tools.Execute(context.Background(), "interface_test", `{"data": "test"}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -851,7 +854,7 @@ func TestNewTool_AnonymousStruct(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("anon_struct", `{"name": "John"}`)
+	result, ok := tools.Execute(context.Background(), "anon_struct", `{"name": "John"}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -892,7 +895,7 @@ func TestNewTool_WithIntPrimitive(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("int_primitive", `{"input": 42}`)
+	result, ok := tools.Execute(context.Background(), "int_primitive", `{"input": 42}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -927,7 +930,7 @@ func TestNewTool_WithBoolPrimitive(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("bool_primitive", `{"input": true}`)
+	result, ok := tools.Execute(context.Background(), "bool_primitive", `{"input": true}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -1040,7 +1043,7 @@ func TestNewTool_WithFloatPrimitive(t *testing.T) {
 		return
 	}
 
-	result, ok := tools.Execute("float_primitive", `{"input": 3.14}`)
+	result, ok := tools.Execute(context.Background(), "float_primitive", `{"input": 3.14}`)
 	if !ok {
 		t.Errorf("Execute() error = %v", result)
 		return
@@ -1050,3 +1053,143 @@ func TestNewTool_WithFloatPrimitive(t *testing.T) {
 		t.Errorf("Execute() result = %v, want %v", result, "ok")
 	}
 }
+
+// ============================================================================
+// Tests for context-aware tools
+// ============================================================================
+
+func TestNewToolCtx_ReceivesContext(t *testing.T) {
+	type ctxKey struct{}
+
+	tool, err := NewToolCtx("ctx_tool", "reads the context", func(ctx context.Context, s SimpleStruct) (string, error) {
+		value, _ := ctx.Value(ctxKey{}).(string)
+		return value + " " + s.Name, nil
+	})
+	if err != nil {
+		t.Fatalf("NewToolCtx() error = %v", err)
+	}
+
+	tools := NewTools()
+	tools.Add(tool)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "hello")
+	result, ok := tools.Execute(ctx, "ctx_tool", `{"name": "John"}`)
+	if !ok || result != "hello John" {
+		t.Errorf("Execute() = %q, %v; want %q, true", result, ok, "hello John")
+	}
+}
+
+func TestExecute_Timeout(t *testing.T) {
+	stopped := make(chan struct{})
+	tool, _ := NewToolCtx("slow", "never finishes in time", func(ctx context.Context, s string) (string, error) {
+		<-ctx.Done()
+		close(stopped)
+		return "", ctx.Err()
+	})
+
+	tools := NewTools()
+	tools.Add(tool, WithTimeout(20*time.Millisecond))
+
+	result, ok := tools.Execute(context.Background(), "slow", `{"input": ""}`)
+	if ok {
+		t.Fatal("Execute() ok = true, want false on timeout")
+	}
+	if !strings.Contains(result, "timed out after 20ms") {
+		t.Errorf("Execute() result = %q, want timeout message", result)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("tool context was not cancelled after timeout")
+	}
+}
+
+func TestExecute_TimeoutIgnoredByTool(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tool, _ := NewTool("stuck", "ignores the context", func(s string) (string, error) {
+		<-release
+		return "done", nil
+	})
+
+	tools := NewTools()
+	tools.Add(tool, WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	result, ok := tools.Execute(context.Background(), "stuck", `{"input": ""}`)
+	if ok || !strings.Contains(result, "timed out") {
+		t.Errorf("Execute() = %q, %v; want timeout", result, ok)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Execute() took %v, should not wait for the tool", elapsed)
+	}
+}
+
+func TestExecute_ParentDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tool, _ := NewTool("stuck", "ignores the context", func(s string) (string, error) {
+		<-release
+		return "done", nil
+	})
+
+	tools := NewTools()
+	tools.Add(tool, WithTimeout(time.Second))
+
+	// the deadline of the session is not the timeout of the tool
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result, ok := tools.Execute(ctx, "stuck", `{"input": ""}`)
+	if ok || !strings.Contains(result, "cancelled") || strings.Contains(result, "timed out") {
+		t.Errorf("Execute() = %q, %v; want cancellation message", result, ok)
+	}
+}
+
+func TestExecute_ContextCancelled(t *testing.T) {
+	tool, _ := NewToolCtx("wait", "waits for cancellation", func(ctx context.Context, s string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	tools := NewTools()
+	tools.Add(tool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	result, ok := tools.Execute(ctx, "wait", `{"input": ""}`)
+	if ok || !strings.Contains(result, "cancelled") {
+		t.Errorf("Execute() = %q, %v; want cancellation message", result, ok)
+	}
+}
+
+func TestExecute_AlreadyCancelled(t *testing.T) {
+	var started atomic.Bool
+	tool, _ := NewTool("side_effect", "ignores the context", func(s string) (string, error) {
+		started.Store(true)
+		return "done", nil
+	})
+
+	tools := NewTools()
+	tools.Add(tool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, ok := tools.Execute(ctx, "side_effect", `{"input": ""}`)
+	if ok || !strings.Contains(result, "cancelled") {
+		t.Errorf("Execute() = %q, %v; want cancellation message", result, ok)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if started.Load() {
+		t.Error("tool function must not be started with a cancelled context")
+	}
+}
@@ -1,9 +1,11 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 )
 
 type tool struct {
@@ -13,9 +15,22 @@ type tool struct {
 	function  toolFunction
 	inputType reflect.Type
 	schema    map[string]any
+
+	readOnly bool
+	approval approvalMode
+	risk     RiskLevel
+	prompt   PromptBuilder
+
+	timeout         time.Duration
+	approvalTimeout time.Duration
+}
+
+// ReadOnly reports whether the tool was added with WithReadOnly
+func (t *tool) ReadOnly() bool {
+	return t.readOnly
 }
 
-type toolFunction func(input string) (string, error)
+type toolFunction func(ctx context.Context, input string) (string, error)
 
 type Tools struct {
 	mu    sync.RWMutex
@@ -64,6 +79,13 @@ func (t *Tools) Add(tool tool, opts ...AddOption) error {
 		}
 	}
 
+	tool.readOnly = config.readOnly
+	tool.approval = config.approval
+	tool.risk = config.risk
+	tool.prompt = config.prompt
+	tool.timeout = config.timeout
+	tool.approvalTimeout = config.approvalTimeout
+
 	t.tools[id] = tool
 	return nil
 }
@@ -100,6 +122,18 @@ func (t *Tools) Remove(name string) (removed bool) {
 	return true
 }
 
+// Get returns the tool registered under the given name
+func (t *Tools) Get(name string) (tool, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tool, ok := t.tools[name]
+	if ok {
+		tool.Id = name
+	}
+	return tool, ok
+}
+
 func (t *Tools) Snapshot() []tool {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
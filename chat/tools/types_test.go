@@ -2,6 +2,7 @@ package tools
 
 import (
 	"testing"
+	"time"
 )
 
 func TestAddMethod(t *testing.T) {
@@ -264,3 +265,80 @@ func TestNextID_ExistsWithStartIncrement(t *testing.T) {
 		t.Errorf("nextID() = %v, want 'test_2'", result)
 	}
 }
+
+func TestGetMethod(t *testing.T) {
+	tools := NewTools()
+	tools.Add(tool{Id: "plain"})
+	tools.Add(tool{Id: "reader"}, WithReadOnly())
+	tools.Add(tool{Id: "original"}, WithOverrideName("renamed"))
+
+	if _, ok := tools.Get("missing"); ok {
+		t.Error("Get() found a tool that was never added")
+	}
+
+	plain, ok := tools.Get("plain")
+	if !ok || plain.ReadOnly() {
+		t.Errorf("Get(plain) = %+v, %v; want non read-only tool", plain, ok)
+	}
+
+	reader, ok := tools.Get("reader")
+	if !ok || !reader.ReadOnly() {
+		t.Errorf("Get(reader) = %+v, %v; want read-only tool", reader, ok)
+	}
+
+	renamed, ok := tools.Get("renamed")
+	if !ok || renamed.Id != "renamed" {
+		t.Errorf("Get(renamed) = %+v, %v; want tool with Id renamed", renamed, ok)
+	}
+}
+
+func TestAdd_ApprovalOptions(t *testing.T) {
+	tools := NewTools()
+	tools.Add(tool{Id: "plain"})
+	tools.Add(tool{Id: "required"}, WithRequiresApproval(), WithRiskLevel(RiskHigh),
+		WithApprovalPrompt(func(arguments string) string { return "run with " + arguments + "?" }))
+	tools.Add(tool{Id: "auto"}, WithAutoApprove(), WithRiskLevel(RiskLow), WithApprovalTimeout(time.Minute))
+
+	plain, _ := tools.Get("plain")
+	if plain.RequiresApproval() || plain.AutoApprove() || plain.Risk() != RiskUnspecified {
+		t.Errorf("plain tool has approval options set: %+v", plain)
+	}
+	if prompt := plain.ApprovalPrompt(`{}`); prompt != "" {
+		t.Errorf("ApprovalPrompt() = %q, want empty prompt", prompt)
+	}
+
+	required, _ := tools.Get("required")
+	if !required.RequiresApproval() || required.AutoApprove() || required.Risk() != RiskHigh {
+		t.Errorf("required tool has wrong approval options: %+v", required)
+	}
+	if prompt := required.ApprovalPrompt(`{"a":1}`); prompt != `run with {"a":1}?` {
+		t.Errorf("ApprovalPrompt() = %q", prompt)
+	}
+
+	auto, _ := tools.Get("auto")
+	if auto.RequiresApproval() || !auto.AutoApprove() || auto.Risk() != RiskLow {
+		t.Errorf("auto tool has wrong approval options: %+v", auto)
+	}
+	if auto.ApprovalTimeout() != time.Minute || plain.ApprovalTimeout() != 0 {
+		t.Errorf("ApprovalTimeout() = %v, %v; want %v, 0", auto.ApprovalTimeout(), plain.ApprovalTimeout(), time.Minute)
+	}
+}
+
+func TestRiskLevel_Text(t *testing.T) {
+	for _, level := range []RiskLevel{RiskUnspecified, RiskLow, RiskMedium, RiskHigh} {
+		text, err := level.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%v) error = %v", level, err)
+		}
+
+		var parsed RiskLevel
+		if err := parsed.UnmarshalText(text); err != nil || parsed != level {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, parsed, err, level)
+		}
+	}
+
+	var parsed RiskLevel
+	if err := parsed.UnmarshalText([]byte("extreme")); err == nil {
+		t.Error("UnmarshalText() accepted unknown risk level")
+	}
+}
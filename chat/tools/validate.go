@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validate checks the arguments of a tool call against the schema of the tool:
+// arguments must be a JSON object with all required properties, without unknown properties
+// (unless the schema allows them) and with values matching the input type of the tool
+func (t *Tools) Validate(name string, arguments string) error {
+	tool, ok := t.Get(name)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrToolNotFound, name)
+	}
+	return tool.Validate(arguments)
+}
+
+// Validate checks the arguments against the schema of the tool (see Tools.Validate)
+func (t *tool) Validate(arguments string) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(arguments), &object); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	schema, err := t.GetSchema()
+	if err != nil {
+		return err
+	}
+	root := resolveSchemaRef(schema)
+
+	if required, ok := root["required"].([]any); ok {
+		for _, property := range required {
+			name, _ := property.(string)
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%w: missing required property %q", ErrInvalidArguments, name)
+			}
+		}
+	}
+
+	if additional, ok := root["additionalProperties"].(bool); ok && !additional {
+		properties, _ := root["properties"].(map[string]any)
+		for name := range object {
+			if _, ok := properties[name]; !ok {
+				return fmt.Errorf("%w: unknown property %q", ErrInvalidArguments, name)
+			}
+		}
+	}
+
+	if t.inputType != nil {
+		ptr := reflect.New(t.inputType)
+		if err := json.Unmarshal([]byte(arguments), ptr.Interface()); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+		}
+	}
+
+	return nil
+}
+
+// resolveSchemaRef returns the definition referenced by the root "$ref" of the schema
+func resolveSchemaRef(schema map[string]any) map[string]any {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema
+	}
+
+	defs, _ := schema["$defs"].(map[string]any)
+	def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	if !ok {
+		return schema
+	}
+	return def
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+)
+
+type ValidateInput struct {
+	Path  string `json:"path"`
+	Lines int    `json:"lines,omitempty"`
+}
+
+func TestValidate(t *testing.T) {
+	structTool, _ := NewTool("read", "", func(input ValidateInput) (string, error) { return "", nil })
+	primitiveTool, _ := NewTool("echo", "", func(input string) (string, error) { return "", nil })
+
+	tools := NewTools()
+	tools.Add(structTool)
+	tools.Add(primitiveTool)
+
+	tests := []struct {
+		name      string
+		tool      string
+		arguments string
+		wantErr   error
+	}{
+		{name: "Valid", tool: "read", arguments: `{"path": "a.txt", "lines": 2}`},
+		{name: "Optional Omitted", tool: "read", arguments: `{"path": "a.txt"}`},
+		{name: "Primitive Input", tool: "echo", arguments: `{"input": "hi"}`},
+		{name: "Missing Required", tool: "read", arguments: `{"lines": 2}`, wantErr: ErrInvalidArguments},
+		{name: "Unknown Property", tool: "read", arguments: `{"path": "a", "mode": "w"}`, wantErr: ErrInvalidArguments},
+		{name: "Type Mismatch", tool: "read", arguments: `{"path": 1}`, wantErr: ErrInvalidArguments},
+		{name: "Not An Object", tool: "read", arguments: `["a.txt"]`, wantErr: ErrInvalidArguments},
+		{name: "Incomplete JSON", tool: "read", arguments: `{"path": "a`, wantErr: ErrInvalidArguments},
+		{name: "Unknown Tool", tool: "write", arguments: `{}`, wantErr: ErrToolNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tools.Validate(tt.tool, tt.arguments)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package chat
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/x2d7/interlude/chat/tools"
 )
@@ -13,6 +14,46 @@ type Chat struct {
 	Tools    *tools.Tools
 
 	DeclinedToolMessage string // default: "Tool call declined"
+
+	// ApprovalPolicy decides on tool calls before they reach the consumer (nil defers every call)
+	ApprovalPolicy ApprovalPolicy
+
+	// ApprovalTimeout limits the time a deferred tool call waits for a verdict (0 waits until the session ends).
+	// Tools added with tools.WithApprovalTimeout use their own timeout
+	ApprovalTimeout time.Duration
+	// ApprovalTimeoutAction is applied to tool calls whose approval expired (default: TimeoutDeny)
+	ApprovalTimeoutAction TimeoutAction
+	ExpiredToolMessage    string // default: "Tool call approval timed out"
+
+	// ParallelToolCalls is the maximum amount of approved tool calls executed at once.
+	// Values below 2 execute tool calls one by one.
+	// Tool messages are added to Messages in the order of the calls, unless the session ends while an earlier
+	// call is unanswered: the messages which were already sent are kept and Chat.Resume adds the rest after them
+	ParallelToolCalls int
+
+	// MaxRounds limits the amount of completion rounds in a single session (0 means no limit)
+	MaxRounds int
+	// MaxIdenticalToolCalls stops the session once the model issued the same tool call
+	// (same name and arguments) this many times in a row (0 disables loop detection)
+	MaxIdenticalToolCalls int
+	// FinalAnswerOnLimit runs one last completion without tools when a limit is reached,
+	// asking the model to answer with the information it already has. Tool calls of that completion are ignored
+	FinalAnswerOnLimit bool
+	FinalAnswerPrompt  string // default: DefaultFinalAnswerPrompt
+
+	// StreamFailurePolicy decides what happens to the output collected before the stream of a completion
+	// failed (default: FailureCommitMarked). Tool calls of a failed completion are never executed,
+	// the ones which were sent are reported with a declined EventToolCallResolved, and the session ends after it
+	StreamFailurePolicy StreamFailurePolicy
+
+	// TokenCoalescing merges consecutive token events delivered by sessions of the chat (see Coalesce)
+	TokenCoalescing CoalesceOptions
+
+	// Hooks intercept sessions of the chat (see Hooks and Chat.Use)
+	Hooks []Hooks
+
+	// tool calls awaited by running sessions (see Chat.ToolCall and Chat.Bind)
+	calls *callRegistry
 }
 
 // Client interface represents the LLM connector client
@@ -30,6 +71,7 @@ type Client interface {
 	//     - Only full message events should be converted: EventUserMessage,
 	//       EventAssistantMessage, EventSystemMessage, EventToolMessage,
 	//       EventToolCall, EventRefusal
+	//   - Should NOT send the Metadata of the events to the provider
 	SyncInput(chat *Chat) Client
 }
 
@@ -53,6 +95,23 @@ func (m *Messages) AddEvent(event StreamEvent) {
 	m.Events = append(m.Events, event)
 }
 
+// updateToolCallArguments replaces the arguments of the stored tool call with the given ID
+func (m *Messages) updateToolCallArguments(callID, arguments string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.Events) - 1; i >= 0; i-- {
+		call, ok := m.Events[i].(EventToolCall)
+		if !ok || call.CallID != callID {
+			continue
+		}
+		call.Content = arguments
+		m.Events[i] = call
+		return true
+	}
+	return false
+}
+
 func (m *Messages) Snapshot() []StreamEvent {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -71,7 +130,16 @@ type Stream[T any] interface {
 
 type Verdict struct {
 	Accepted bool
-	call     EventToolCall
+	// Reason is sent to the model instead of Chat.DeclinedToolMessage when the call is declined
+	Reason string
+	// Arguments replace the arguments generated by the model when the call is accepted (empty keeps them)
+	Arguments string
+	// Supplied skips the execution of the accepted call, Result is used as the tool result instead
+	Supplied bool
+	Result   string
+
+	call       EventToolCall
+	endSession bool
 }
 
 type ApproveWaiter struct {
@@ -20,6 +20,11 @@ type OpenAIClient struct {
 	// Params used to generate the response
 	Params openai.ChatCompletionNewParams
 
+	// IncludeUsage requests the token usage of every completion, it's reported with chat.EventUsage.
+	// It sets stream_options.include_usage unless Params.StreamOptions already sets it,
+	// some OpenAI-compatible servers reject requests with stream_options
+	IncludeUsage bool
+
 	RequestOptions []option.RequestOption
 }
 
@@ -35,6 +40,10 @@ func (c *OpenAIClient) NewStreaming(ctx context.Context) chat.Stream[chat.Stream
 		params.Model = c.Model
 	}
 
+	if c.IncludeUsage && !params.StreamOptions.IncludeUsage.Valid() {
+		params.StreamOptions.IncludeUsage = openai.Bool(true)
+	}
+
 	stream.SSEStream = client.Chat.Completions.NewStreaming(ctx, params)
 	return stream
 }
@@ -56,6 +65,15 @@ func (c *OpenAIClient) SyncInput(chat *chat.Chat) chat.Client {
 	return &newClient
 }
 
+// WithToolChoice returns a copy of the client which forces the model to call the named function (see chat.Extract)
+func (c *OpenAIClient) WithToolChoice(name string) chat.Client {
+	newClient := *c
+	newClient.Params.ToolChoice = openai.ToolChoiceOptionFunctionToolChoice(
+		openai.ChatCompletionNamedToolChoiceFunctionParam{Name: name},
+	)
+	return &newClient
+}
+
 type openAIMessages []openai.ChatCompletionMessageParamUnion
 
 func (m *openAIMessages) findLastAssistantMessage() *openai.ChatCompletionMessageParamUnion {
@@ -1,6 +1,11 @@
 package openai_connect
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/openai/openai-go/v3"
@@ -507,3 +512,78 @@ func TestSyncInput_PreservesModelAndAPIKey(t *testing.T) {
 		t.Errorf("Expected Endpoint to be preserved, got '%s'", newClient.Endpoint)
 	}
 }
+
+// ==================== WithToolChoice Tests ====================
+
+func TestWithToolChoice_ForcesFunction(t *testing.T) {
+	original := &OpenAIClient{Model: "gpt-4o"}
+
+	var _ chat.ToolChoiceClient = original
+
+	result, ok := original.WithToolChoice("extract").(*OpenAIClient)
+	if !ok {
+		t.Fatal("WithToolChoice should return an *OpenAIClient")
+	}
+
+	choice := result.Params.ToolChoice.OfFunctionToolChoice
+	if choice == nil || choice.Function.Name != "extract" {
+		t.Errorf("expected tool choice of function %q, got %+v", "extract", result.Params.ToolChoice)
+	}
+	if original.Params.ToolChoice.OfFunctionToolChoice != nil {
+		t.Error("WithToolChoice should not modify the original client")
+	}
+	if result.Model != "gpt-4o" {
+		t.Errorf("expected model to be preserved, got %q", result.Model)
+	}
+}
+
+// ==================== NewStreaming Tests ====================
+
+// requestedStreamOptions returns the stream_options of the request sent by NewStreaming
+func requestedStreamOptions(t *testing.T, client *OpenAIClient) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("failed to decode the request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client.Endpoint = server.URL
+	client.APIKey = "test"
+	stream := client.NewStreaming(context.Background())
+	for stream.Next(context.Background()) {
+	}
+	stream.Close()
+
+	options, _ := body["stream_options"].(map[string]any)
+	return options
+}
+
+func TestNewStreaming_UsageNotRequestedByDefault(t *testing.T) {
+	if options := requestedStreamOptions(t, &OpenAIClient{Model: "gpt-4o"}); options != nil {
+		t.Errorf("expected no stream_options, got %v", options)
+	}
+}
+
+func TestNewStreaming_IncludeUsage(t *testing.T) {
+	options := requestedStreamOptions(t, &OpenAIClient{Model: "gpt-4o", IncludeUsage: true})
+	if options["include_usage"] != true {
+		t.Errorf("expected include_usage to be requested, got %v", options)
+	}
+}
+
+func TestNewStreaming_IncludeUsageKeepsStreamOptions(t *testing.T) {
+	client := &OpenAIClient{Model: "gpt-4o", IncludeUsage: true}
+	client.Params.StreamOptions.IncludeUsage = openai.Bool(false)
+
+	options := requestedStreamOptions(t, client)
+	if options["include_usage"] != false {
+		t.Errorf("expected include_usage of the params to be kept, got %v", options)
+	}
+}
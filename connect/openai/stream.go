@@ -23,6 +23,8 @@ type OpenAIStream struct {
 	queue []chat.StreamEvent
 	err   error
 	cur   chat.StreamEvent
+	// described is set once EventGeneration was emitted
+	described bool
 
 	OpenAIClient *OpenAIClient
 	SSEStream    sseStreamer
@@ -99,10 +101,28 @@ func (s *OpenAIStream) handleRawChunk(chunk openai.ChatCompletionChunk) ([]chat.
 // Should not return empty list. It would be considered as an error
 func (s *OpenAIStream) _handleRawChunk(chunk openai.ChatCompletionChunk) ([]chat.StreamEvent, error) {
 	result := make([]chat.StreamEvent, 0)
-	if len(chunk.Choices) == 0 {
-		return result, nil
+
+	// every chunk carries the generation details, they're reported once
+	if !s.described && chunk.ID != "" {
+		s.described = true
+		result = append(result, chat.NewEventGeneration(chunk.Model, chunk.ID))
+	}
+
+	if len(chunk.Choices) != 0 {
+		result = append(result, s.choiceEvents(chunk.Choices[0])...)
 	}
-	choice := chunk.Choices[0]
+
+	// the last chunk carries the usage when stream_options.include_usage is set
+	if chunk.JSON.Usage.Valid() {
+		result = append(result, chat.NewEventUsage(convertUsage(chunk.Usage)))
+	}
+
+	return result, nil
+}
+
+// choiceEvents extracts list of events from the delta of the choice
+func (s *OpenAIStream) choiceEvents(choice openai.ChatCompletionChunkChoice) []chat.StreamEvent {
+	result := make([]chat.StreamEvent, 0)
 
 	delta := choice.Delta
 
@@ -137,5 +157,15 @@ func (s *OpenAIStream) _handleRawChunk(chunk openai.ChatCompletionChunk) ([]chat
 		result = append(result, chat.NewEventToolCall(tool.ID, name, arguments))
 	}
 
-	return result, nil
+	return result
+}
+
+func convertUsage(usage openai.CompletionUsage) chat.Usage {
+	return chat.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		ReasoningTokens:  usage.CompletionTokensDetails.ReasoningTokens,
+		CachedTokens:     usage.PromptTokensDetails.CachedTokens,
+	}
 }
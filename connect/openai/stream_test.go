@@ -539,3 +539,90 @@ func TestNext_ContextCancellation_StopsStream(t *testing.T) {
 		t.Errorf("Expected context.Canceled error, got %v", s.Err())
 	}
 }
+
+// ==================== Usage Tests ====================
+
+func TestHandleRawChunk_UsageChunk(t *testing.T) {
+	s := newStream(newMockSSEStream(nil, nil))
+	rawJSON := `{"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15,` +
+		`"completion_tokens_details":{"reasoning_tokens":2},"prompt_tokens_details":{"cached_tokens":4}}}`
+	var chunk openai.ChatCompletionChunk
+	if err := json.Unmarshal([]byte(rawJSON), &chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := s._handleRawChunk(chunk)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	usage, ok := events[0].(chat.EventUsage)
+	if !ok {
+		t.Fatalf("Expected EventUsage, got %T", events[0])
+	}
+	expected := chat.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, ReasoningTokens: 2, CachedTokens: 4}
+	if usage.Usage != expected {
+		t.Errorf("Expected usage %+v, got %+v", expected, usage.Usage)
+	}
+}
+
+func TestHandleRawChunk_NullUsage_Ignored(t *testing.T) {
+	s := newStream(newMockSSEStream(nil, nil))
+	var chunk openai.ChatCompletionChunk
+	if err := json.Unmarshal([]byte(`{"choices":[{"delta":{"content":"Hi"}}],"usage":null}`), &chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := s._handleRawChunk(chunk)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if _, ok := events[0].(chat.EventToken); !ok {
+		t.Errorf("Expected EventToken, got %T", events[0])
+	}
+}
+
+// ==================== Generation Tests ====================
+
+func TestHandleRawChunk_GenerationReportedOnce(t *testing.T) {
+	s := newStream(newMockSSEStream(nil, nil))
+
+	var events []chat.StreamEvent
+	for _, raw := range []string{
+		`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"delta":{"content":"Hel"}}]}`,
+		`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"delta":{"content":"lo"}}]}`,
+	} {
+		var chunk openai.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+			t.Fatal(err)
+		}
+		chunkEvents, err := s._handleRawChunk(chunk)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		events = append(events, chunkEvents...)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+	generation, ok := events[0].(chat.EventGeneration)
+	if !ok {
+		t.Fatalf("Expected EventGeneration, got %T", events[0])
+	}
+	if generation != chat.NewEventGeneration("gpt-4o", "chatcmpl-1") {
+		t.Errorf("Unexpected generation %+v", generation)
+	}
+	for _, event := range events[1:] {
+		if _, ok := event.(chat.EventToken); !ok {
+			t.Errorf("Expected EventToken, got %T", event)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 // approval-flow demonstrates user-controlled tool call approval.
-// The assistant wants to execute tools — you decide whether to allow each one.
+// The assistant wants to execute tools — safe reads run immediately,
+// writes wait for you to decide whether to allow them.
 package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -73,8 +75,18 @@ func main() {
 		return fmt.Sprintf("written %d bytes to %s", len(input.Content), input.Path), nil
 	})
 
-	toolList.Add(readTool)
-	toolList.Add(writeTool)
+	toolList.Add(readTool, tools.WithAutoApprove(), tools.WithRiskLevel(tools.RiskLow))
+	toolList.Add(writeTool,
+		tools.WithRequiresApproval(),
+		tools.WithRiskLevel(tools.RiskHigh),
+		tools.WithApprovalPrompt(func(arguments string) string {
+			var input WriteFileInput
+			if err := json.Unmarshal([]byte(arguments), &input); err != nil {
+				return fmt.Sprintf("Write file with arguments %s?", arguments)
+			}
+			return fmt.Sprintf("Write %d bytes to %q?", len(input.Content), input.Path)
+		}),
+	)
 
 	c := chat.Chat{
 		Messages: chat.NewMessages(),
@@ -87,35 +99,30 @@ func main() {
 	prompt := `Read the file "input.txt", then write its contents in uppercase to "output.txt".`
 	fmt.Printf("Prompt: %s\n\n", prompt)
 
-	pendingCalls := make([]chat.EventToolCall, 0)
-
-	for event := range c.SendUserStream(context.Background(), &client, prompt) {
-		switch v := event.(type) {
-		case chat.EventToken:
-			fmt.Print(v.Content)
-
-		case chat.EventToolCall:
-			pendingCalls = append(pendingCalls, v)
-
-		case chat.EventCompletionEnded:
+	c.AddMessage(chat.SenderUser{}, prompt)
+
+	// auto-approved calls are resolved by the tool policies, only the rest reaches OnToolCall
+	err := c.Run(context.Background(), &client, chat.Handler{
+		OnToken: func(text string) {
+			fmt.Print(text)
+		},
+		OnToolCall: func(call chat.EventToolCall) chat.Verdict {
+			fmt.Print("\033[31m")
+			fmt.Printf("\n Tool: %s (risk: %s)\n", call.Name, call.Risk)
+			fmt.Printf("   %s (y/n): ", call.Prompt)
+			fmt.Print("\033[0m")
+
+			var answer string
+			fmt.Scanln(&answer)
+			return chat.Verdict{Accepted: answer == "y"}
+		},
+		OnCompletionEnd: func(chat.EventCompletionEnded) {
 			fmt.Println()
-			for _, call := range pendingCalls {
-				fmt.Print("\033[31m")
-				fmt.Printf("\n Tool: %s\n", call.Name)
-				fmt.Printf("   Args: %s\n", call.Content)
-				fmt.Print("   Allow? (y/n): ")
-				fmt.Print("\033[0m")
-
-				var answer string
-				fmt.Scanln(&answer)
-				call.Resolve(answer == "y")
-			}
-			pendingCalls = pendingCalls[:0]
-
-		case chat.EventError:
-			fmt.Fprintf(os.Stderr, "\nerror: %s\n", v.Error)
-			os.Exit(1)
-		}
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nerror: %s\n", err)
+		os.Exit(1)
 	}
 
 	fmt.Println()